@@ -2,18 +2,24 @@ package main
 
 import (
 	"context"
+	"dmmak/redditapi/internal/api"
 	"dmmak/redditapi/internal/auth"
 	config "dmmak/redditapi/internal/config"
 	client "dmmak/redditapi/internal/redditclient"
+	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 )
 
+const revokeTimeout = 10 * time.Second
+
 var configPath string
 var logPath string
 
@@ -36,6 +42,48 @@ func setLogger(path string) (f *os.File) {
 	return f
 }
 
+func newCredentialsProvider(cfg config.CredentialsConfig, envPrefix string) (cp auth.CredentialsProvider) {
+	switch cfg.Source {
+	case config.CredentialsFile:
+		return auth.NewSecretsFileCredentials(cfg.Path)
+	case config.CredentialsCommand:
+		return auth.NewCommandCredentials(cfg.Command)
+	}
+	return auth.NewEnvCredentials(envPrefix)
+}
+
+func newTokenPoller(acc config.AccountConfig) (tp api.AuthTokenPoller, err error) {
+	cfg := acc.Auth
+	cp := newCredentialsProvider(cfg.Credentials, acc.EnvPrefix)
+	creds, err := cp.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	opts := []auth.Option{auth.WithCredentialsProvider(cp)}
+	if cfg.TokenStore != "" {
+		opts = append(opts, auth.WithTokenStore(auth.NewFileTokenStore(cfg.TokenStore, cfg.TokenPassphrase)))
+	}
+	if cfg.RevokeUrl != "" {
+		opts = append(opts, auth.WithRevokeURL(cfg.RevokeUrl))
+	}
+	switch cfg.Flow {
+	case config.FlowCode:
+		tp = auth.NewCodeFlowTokenPoller(cfg.Host, cfg.RequestPeriod, cfg.MaxOutage, creds,
+			auth.CodeFlowParams{
+				AuthorizeURL: cfg.AuthorizeUrl,
+				RedirectURI:  cfg.RedirectUri,
+				Scopes:       cfg.ScopeList(),
+			}, opts...)
+	case config.FlowClientCredentials:
+		tp = auth.NewClientCredentialsTokenPoller(cfg.Host, cfg.RequestPeriod, cfg.MaxOutage, creds, opts...)
+	case config.FlowInstalledClient:
+		tp = auth.NewInstalledClientTokenPoller(cfg.Host, cfg.RequestPeriod, cfg.MaxOutage, creds, cfg.DeviceId, opts...)
+	default:
+		tp = auth.NewTokenPoller(cfg.Host, cfg.RequestPeriod, cfg.MaxOutage, creds, opts...)
+	}
+	return tp, nil
+}
+
 func main() {
 	log.Println("Application startup")
 
@@ -50,36 +98,91 @@ func main() {
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	wg := &sync.WaitGroup{}
+	pollersWg := &sync.WaitGroup{}
 
-	tp := auth.NewTokenPoller(cfg.Auth.Host, cfg.Auth.RequestPeriod,
-		auth.Credentials{
-			UserName:     cfg.Auth.Username,
-			Password:     cfg.Auth.Password,
-			ClientId:     cfg.Auth.ClientId,
-			ClientSecret: cfg.Auth.ClientSecret,
-		})
-	authExit, err := tp.Start(ctx)
-	if err != nil {
-		log.Fatalf("Error while starting auth token polling, %v", err)
+	// every account has own token poller and rate limited client
+	authFailed := make(chan error, len(cfg.Accounts))
+	pollers := make(map[string]api.AuthTokenPoller)
+	clients := make(map[string]api.RedditAPIClient)
+	for _, acc := range cfg.Accounts {
+		tp, err := newTokenPoller(acc)
+		if err != nil {
+			log.Fatalf("Error while loading credentials for account %q, %v", acc.Name, err)
+		}
+		authExit, err := tp.Start(ctx)
+		if err != nil {
+			log.Fatalf("Error while starting auth token polling for account %q, %v", acc.Name, err)
+		}
+		pollersWg.Add(1)
+		go func(name string, authExit <-chan error) {
+			defer pollersWg.Done()
+			for err := range authExit {
+				authFailed <- fmt.Errorf("account %q: %w", name, err)
+			}
+		}(acc.Name, authExit)
+		status := tp.Status()
+		log.Printf("Account %q authorized, scopes: %v, token expires at: %v\n", acc.Name, status.Scopes, status.ExpiresAt)
+		pollers[acc.Name] = tp
+		clients[acc.Name] = client.NewClient(cfg.Client.Host, cfg.Client.NewPostsUrl, cfg.Client.SavePostUrl, cfg.Client.UserAgent, tp)
+		// log the Reddit user behind the account to catch credentials mix-ups
+		if !tp.AppOnly() {
+			if me, err := clients[acc.Name].Me(ctx); err != nil {
+				log.Printf("Error while getting Reddit user of account %q: %v\n", acc.Name, err)
+			} else {
+				log.Printf("Account %q acts as u/%v\n", acc.Name, me.Name)
+			}
+		}
+	}
+
+	// fail early if workers aren't allowed to do their job
+	for _, sub := range cfg.Client.Subreddits {
+		methods := client.WorkerMethods
+		if pollers[sub.Account].AppOnly() {
+			methods = client.ReadOnlyWorkerMethods
+		}
+		if err := clients[sub.Account].VerifyScopes(methods...); err != nil {
+			log.Fatalf("Account %q can't be used for subreddit %q, %v", sub.Account, sub.Name, err)
+		}
+		err := client.VerifySubreddit(ctx, clients[sub.Account], sub.Name)
+		var subErr *client.SubredditError
+		if errors.As(err, &subErr) {
+			log.Fatalf("Subreddit %q can't be monitored, %v", sub.Name, err)
+		} else if err != nil {
+			// network or server errors could be transient, so worker retries polling on its own
+			log.Printf("Error while verifying subreddit %q: %v\n", sub.Name, err)
+		}
 	}
 
-	cl := client.NewClient(cfg.Client.Host, cfg.Client.NewPostsUrl, cfg.Client.SavePostUrl, cfg.Client.UserAgent, tp)
 	// start monitoring subreddits
 	for _, sub := range cfg.Client.Subreddits {
 		wg.Add(1)
 		go func(sub config.Subreddit) {
 			defer wg.Done()
-			client.NewWorker(sub.Name, sub.Keywords, cfg.Client.RequestPeriod, cl).DoWork(ctx)
+			// app-only accounts can't save posts, so their workers only log matching ones
+			if pollers[sub.Account].AppOnly() {
+				client.NewReadOnlyWorker(sub.Name, sub.Keywords, cfg.Client.RequestPeriod, clients[sub.Account]).DoWork(ctx)
+				return
+			}
+			client.NewWorker(sub.Name, sub.Keywords, cfg.Client.RequestPeriod, clients[sub.Account]).DoWork(ctx)
 		}(sub)
 	}
 
 	select {
-	case <-authExit:
+	case err := <-authFailed:
+		log.Println(err)
 		cancel()
 	case <-ctx.Done():
 		log.Println("Shutdown signal recieved")
 	}
 	wg.Wait()
-	<-authExit
+	pollersWg.Wait()
+
+	revokeCtx, revokeCancel := context.WithTimeout(context.Background(), revokeTimeout)
+	defer revokeCancel()
+	for name, tp := range pollers {
+		if err := tp.Revoke(revokeCtx); err != nil {
+			log.Printf("Error while revoking auth token for account %q: %v\n", name, err)
+		}
+	}
 	log.Println("Gracefully shutdowned")
 }
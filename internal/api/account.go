@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	// Account is a Reddit user account (t2)
+	Account struct {
+		Id               string    `json:"id"`
+		Name             string    `json:"name"` // username
+		LinkKarma        int       `json:"link_karma"`
+		CommentKarma     int       `json:"comment_karma"`
+		TotalKarma       int       `json:"total_karma"`
+		IsGold           bool      `json:"is_gold"`
+		IsMod            bool      `json:"is_mod"`
+		IsEmployee       bool      `json:"is_employee"`
+		Verified         bool      `json:"verified"`
+		HasVerifiedEmail bool      `json:"has_verified_email"`
+		IsSuspended      bool      `json:"is_suspended"`
+		IconImg          string    `json:"icon_img"`
+		Created          time.Time `json:"-"`
+	}
+
+	// SubredditKarma is karma of the authorized user earned in a subreddit
+	SubredditKarma struct {
+		Subreddit    string `json:"sr"`
+		LinkKarma    int    `json:"link_karma"`
+		CommentKarma int    `json:"comment_karma"`
+	}
+
+	// Prefs are preferences of the authorized user, keyed by Reddit preference names, e.g. "over_18"
+	Prefs map[string]interface{}
+
+	// Award is a trophy of user account (t6)
+	Award struct {
+		Id          string    `json:"id"`
+		AwardId     string    `json:"award_id"`
+		Name        string    `json:"name"`
+		Description string    `json:"description"`
+		Icon70      string    `json:"icon_70"`
+		URL         string    `json:"url"`
+		Granted     time.Time `json:"-"`
+	}
+)
+
+func (a *Account) UnmarshalJSON(b []byte) (err error) {
+	type account Account
+	a.Created, err = unmarshalCreated(b, (*account)(a))
+	return err
+}
+
+func (a *Award) UnmarshalJSON(b []byte) (err error) {
+	type award Award
+	aux := struct {
+		*award
+		GrantedAt float64 `json:"granted_at"`
+	}{award: (*award)(a)}
+	err = json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+	a.Granted = unixTime(aux.GrantedAt)
+	return nil
+}
@@ -1,32 +1,227 @@
 package api
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// OAuth scopes required by API methods
+const (
+	ScopeAll      = "*" // granted to script apps
+	ScopeIdentity = "identity"
+	ScopeRead     = "read"
+	ScopeSave     = "save"
+	ScopeSubmit   = "submit"
+	ScopeVote     = "vote"
+	ScopeEdit     = "edit"
+	ScopeReport   = "report" // hiding and reporting
+	ScopeModPosts = "modposts"
+	ScopeHistory  = "history"
+	ScopeAccount  = "account" // updating preferences
+
+	ScopePrivateMessages = "privatemessages"
+	ScopeMySubreddits    = "mysubreddits"
+)
+
+// Kinds of submitted posts
+const (
+	SubmitSelf      = "self"
+	SubmitLink      = "link"
+	SubmitCrosspost = "crosspost"
+)
+
+// Distinguish values
+const (
+	DistinguishYes     = "yes" // moderator
+	DistinguishNo      = "no"
+	DistinguishAdmin   = "admin"
+	DistinguishSpecial = "special"
+)
+
+// Vote directions
+const (
+	VoteUp   = 1
+	VoteNone = 0
+	VoteDown = -1
+)
+
+// Time periods of top and controversial listings
+const (
+	TimeHour  = "hour"
+	TimeDay   = "day"
+	TimeWeek  = "week"
+	TimeMonth = "month"
+	TimeYear  = "year"
+	TimeAll   = "all"
+)
+
+// Search result sorts
+const (
+	SearchSortRelevance = "relevance"
+	SearchSortHot       = "hot"
+	SearchSortTop       = "top"
+	SearchSortNew       = "new"
+	SearchSortComments  = "comments"
+)
+
+// Search result types
+const (
+	SearchTypeLink      = "link"
+	SearchTypeSubreddit = "sr"
+	SearchTypeUser      = "user"
+)
 
 type (
 	AuthTokenPoller interface {
-		Start(ctx context.Context) (exit chan struct{}, err error)
+		Start(ctx context.Context) (exit <-chan error, err error)
 		TokenValue() string
+		ExpiresAt() time.Time
+		ForceRefresh(ctx context.Context, staleToken string) error
+		// WaitToken waits for in-flight or scheduled refresh if current token is expired
+		WaitToken(ctx context.Context) (string, error)
+		AppOnly() bool
+		Revoke(ctx context.Context) error
+		Status() TokenStatus
 	}
 
-	RedditAPIClient interface {
-		GetNewPosts(ctx context.Context, subreddit, lastPostName string) (r *NewPostsResponse, err error)
-		SavePost(ctx context.Context, name string) error
+	// TokenStatus is a snapshot of auth token state for health reporting
+	TokenStatus struct {
+		Scopes           []string // nil if auth server didn't report granted scopes
+		IssuedAt         time.Time
+		ExpiresAt        time.Time // zero if server didn't report token lifetime
+		LastRefreshError error     // nil if last refresh succeeded
+	}
+
+	// ListingOptions are common parameters of listing endpoints
+	ListingOptions struct {
+		Limit  int    // page size up to 100, 25 if not set
+		After  string // fullname to start after
+		Before string // fullname to start before, pages are walked backwards if it's set
+		Max    int    // stop after this number of things, zero means no bound
+	}
+
+	// SearchOptions are parameters of search request
+	SearchOptions struct {
+		ListingOptions
+		Subreddit string   // restrict search to the subreddit, site-wide search if not set
+		Sort      string   // one of SearchSort* values, relevance if not set
+		Time      string   // one of Time* periods, all if not set
+		Types     []string // SearchType* values, links only if not set
 	}
 
-	NewPostsResponse struct {
-		Data NewPostsResponseData `json:"data"`
+	// UserHistoryOptions are parameters of user history listings
+	UserHistoryOptions struct {
+		ListingOptions
+		Sort string // hot, new, top or controversial, new if not set
+		Time string // one of Time* periods for top and controversial sorts
 	}
 
-	NewPostsResponseData struct {
-		Children []NewPostsResponseChildren `json:"children"`
+	// SubmitRequest describes a new post
+	SubmitRequest struct {
+		Subreddit     string
+		Kind          string // one of Submit* kinds
+		Title         string
+		Text          string // markdown body of self post
+		Url           string // url of link post
+		CrosspostName string // fullname of crossposted post
+		FlairId       string
+		FlairText     string
+		NSFW          bool
+		Spoiler       bool
+		SendReplies   bool // send replies to inbox
+		Resubmit      bool // submit link even if it has been submitted to the subreddit already
 	}
 
-	NewPostsResponseChildren struct {
-		Data NewPostsResponseChildrenData `json:"data"`
+	// Submitted is a post created by Submit
+	Submitted struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+		Url  string `json:"url"`
 	}
 
-	NewPostsResponseChildrenData struct {
-		Title string `json:"title"`
-		Name  string `json:"name"`
+	// ListingIterator walks listing pages:
+	//
+	//	for it.Next(ctx) {
+	//		page := it.Page()
+	//	}
+	//	err := it.Err()
+	ListingIterator interface {
+		Next(ctx context.Context) bool
+		Page() *Listing
+		Count() int // number of things seen so far
+		Err() error
+	}
+
+	RedditAPIClient interface {
+		GetNewPosts(ctx context.Context, subreddit, lastPostName string) (r *Listing, err error)
+		NewPosts(subreddit string, opts ListingOptions) ListingIterator
+		HotPosts(subreddit string, geoFilter string, opts ListingOptions) ListingIterator
+		TopPosts(subreddit string, period string, opts ListingOptions) ListingIterator
+		ControversialPosts(subreddit string, period string, opts ListingOptions) ListingIterator
+		RisingPosts(subreddit string, opts ListingOptions) ListingIterator
+		Search(query string, opts SearchOptions) ListingIterator
+		// SavePost saves the post or comment, category is optional
+		SavePost(ctx context.Context, name string, category string) error
+		UnsavePost(ctx context.Context, name string) error
+		Hide(ctx context.Context, names ...string) error
+		Unhide(ctx context.Context, names ...string) error
+		Vote(ctx context.Context, name string, dir int) error
+		MarkNSFW(ctx context.Context, name string, nsfw bool) error
+		Spoiler(ctx context.Context, name string, spoiler bool) error
+		Report(ctx context.Context, name string, reason string) error
+		Submit(ctx context.Context, r SubmitRequest) (post *Submitted, err error)
+		// Comment replies to the post or comment, messages are replied with ReplyToMessage
+		Comment(ctx context.Context, parent string, markdown string) (comment *Comment, err error)
+		EditUserText(ctx context.Context, name string, markdown string) (thing *Thing, err error)
+		Delete(ctx context.Context, name string) error
+		Inbox(box string, opts ListingOptions) ListingIterator
+		Compose(ctx context.Context, to string, subject string, markdown string) error
+		ReplyToMessage(ctx context.Context, parent string, markdown string) (message *Message, err error)
+		MarkRead(ctx context.Context, names ...string) error
+		MarkUnread(ctx context.Context, names ...string) error
+		DeleteMessage(ctx context.Context, name string) error
+		SubredditAbout(ctx context.Context, name string) (sub *Subreddit, err error)
+		SubredditSidebar(ctx context.Context, name string) (markdown string, err error)
+		SubredditRules(ctx context.Context, name string) (rules []*Rule, err error)
+		SubredditModerators(ctx context.Context, name string) (mods []*Moderator, err error)
+		UserAbout(ctx context.Context, username string) (account *Account, err error)
+		UserSubmitted(username string, opts UserHistoryOptions) ListingIterator
+		UserComments(username string, opts UserHistoryOptions) ListingIterator
+		UserSaved(username string, opts UserHistoryOptions) ListingIterator
+		UserUpvoted(username string, opts UserHistoryOptions) ListingIterator
+		// Me returns the account the client acts as
+		Me(ctx context.Context) (account *Account, err error)
+		MyKarma(ctx context.Context) (karma []*SubredditKarma, err error)
+		MyPrefs(ctx context.Context) (prefs Prefs, err error)
+		// PatchMyPrefs updates given preferences and returns all preferences
+		PatchMyPrefs(ctx context.Context, prefs Prefs) (updated Prefs, err error)
+		Approve(ctx context.Context, name string) error
+		Remove(ctx context.Context, name string, spam bool) error
+		Lock(ctx context.Context, name string) error
+		Unlock(ctx context.Context, name string) error
+		Sticky(ctx context.Context, name string, state bool, slot int) error
+		Distinguish(ctx context.Context, name string, how string, sticky bool) error
+		SetContestMode(ctx context.Context, name string, enabled bool) error
+		SetSuggestedSort(ctx context.Context, name string, sort string) error
+		IgnoreReports(ctx context.Context, name string, ignore bool) error
+		GetComments(ctx context.Context, article string, opts CommentsOptions) (thread *Thread, err error)
+		// ExpandMore replaces "more" stubs in the thread with comments they stand for
+		ExpandMore(ctx context.Context, thread *Thread) error
+		// VerifyScopes checks that auth token allows to call given API methods
+		VerifyScopes(methods ...string) error
 	}
 )
+
+// Allows reports whether the token is granted the scope. Unknown scopes allow everything,
+// so the server has the final say.
+func (s TokenStatus) Allows(scope string) bool {
+	if s.Scopes == nil {
+		return true
+	}
+	for _, v := range s.Scopes {
+		if v == ScopeAll || v == scope {
+			return true
+		}
+	}
+	return false
+}
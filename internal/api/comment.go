@@ -0,0 +1,74 @@
+package api
+
+import "time"
+
+type (
+	// Comment is a Reddit comment (t1)
+	Comment struct {
+		Id                  string    `json:"id"`
+		Name                string    `json:"name"` // fullname, e.g. t1_jsa7x2c
+		Author              string    `json:"author"`
+		AuthorFullname      string    `json:"author_fullname"`
+		Body                string    `json:"body"`
+		LinkId              string    `json:"link_id"`   // fullname of the post
+		ParentId            string    `json:"parent_id"` // fullname of the post or parent comment
+		LinkTitle           string    `json:"link_title"`
+		Subreddit           string    `json:"subreddit"`
+		SubredditId         string    `json:"subreddit_id"`
+		Permalink           string    `json:"permalink"`
+		Score               int       `json:"score"`
+		Depth               int       `json:"depth"`
+		Controversiality    int       `json:"controversiality"`
+		AuthorFlairText     string    `json:"author_flair_text"`
+		AuthorFlairCSSClass string    `json:"author_flair_css_class"`
+		Distinguished       string    `json:"distinguished"` // moderator, admin or empty
+		IsSubmitter         bool      `json:"is_submitter"`
+		Stickied            bool      `json:"stickied"`
+		Locked              bool      `json:"locked"`
+		Archived            bool      `json:"archived"`
+		ScoreHidden         bool      `json:"score_hidden"`
+		Replies             *Listing  `json:"replies"`
+		Created             time.Time `json:"-"`
+	}
+
+	// Thread is a post along with its comment tree
+	Thread struct {
+		Post     *Link
+		Comments *Listing // top level comments, replies are nested into comments
+		Sort     string   // sort comments were requested with, used to expand "more" stubs
+	}
+
+	// CommentsOptions are parameters of comment tree request
+	CommentsOptions struct {
+		Sort    string // one of CommentSort* values, Reddit's default if not set
+		Depth   int    // max depth of the tree, zero means Reddit's default
+		Limit   int    // max number of comments, zero means Reddit's default
+		Comment string // id of the comment to be the root of the tree, optional
+	}
+
+	// More is a stub of comments which weren't included into comment tree
+	More struct {
+		Id       string   `json:"id"`
+		Name     string   `json:"name"`
+		ParentId string   `json:"parent_id"`
+		Count    int      `json:"count"`
+		Depth    int      `json:"depth"`
+		Children []string `json:"children"` // ids of missing comments
+	}
+)
+
+// Comment sorts
+const (
+	CommentSortConfidence    = "confidence"
+	CommentSortTop           = "top"
+	CommentSortNew           = "new"
+	CommentSortControversial = "controversial"
+	CommentSortOld           = "old"
+	CommentSortQA            = "qa"
+)
+
+func (c *Comment) UnmarshalJSON(b []byte) (err error) {
+	type comment Comment
+	c.Created, err = unmarshalCreated(b, (*comment)(c))
+	return err
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// Link is a Reddit post (t3)
+	Link struct {
+		Id                  string  `json:"id"`
+		Name                string  `json:"name"` // fullname, e.g. t3_151s97h
+		Title               string  `json:"title"`
+		Author              string  `json:"author"`
+		AuthorFullname      string  `json:"author_fullname"`
+		Subreddit           string  `json:"subreddit"`
+		SubredditId         string  `json:"subreddit_id"`
+		Selftext            string  `json:"selftext"`
+		URL                 string  `json:"url"`
+		Domain              string  `json:"domain"`
+		Permalink           string  `json:"permalink"`
+		Score               int     `json:"score"`
+		UpvoteRatio         float64 `json:"upvote_ratio"`
+		NumComments         int     `json:"num_comments"`
+		LinkFlairText       string  `json:"link_flair_text"`
+		LinkFlairCSSClass   string  `json:"link_flair_css_class"`
+		AuthorFlairText     string  `json:"author_flair_text"`
+		AuthorFlairCSSClass string  `json:"author_flair_css_class"`
+		Over18              bool    `json:"over_18"`
+		Spoiler             bool    `json:"spoiler"`
+		Stickied            bool    `json:"stickied"`
+		Locked              bool    `json:"locked"`
+		Archived            bool    `json:"archived"`
+		IsSelf              bool    `json:"is_self"`
+		IsVideo             bool    `json:"is_video"`
+		IsGallery           bool    `json:"is_gallery"`
+		// fullname of the original post if this one is a crosspost
+		CrosspostParent     string                   `json:"crosspost_parent"`
+		CrosspostParentList []Link                   `json:"crosspost_parent_list"`
+		Media               *Media                   `json:"media"`
+		GalleryData         *GalleryData             `json:"gallery_data"`
+		MediaMetadata       map[string]MediaMetadata `json:"media_metadata"` // gallery and inline media by media id
+		Created             time.Time                `json:"-"`
+	}
+
+	Media struct {
+		Type        string       `json:"type"` // oembed provider, e.g. youtube.com
+		RedditVideo *RedditVideo `json:"reddit_video"`
+		Oembed      *Oembed      `json:"oembed"`
+	}
+
+	RedditVideo struct {
+		FallbackURL string `json:"fallback_url"`
+		HLSURL      string `json:"hls_url"`
+		DashURL     string `json:"dash_url"`
+		Width       int    `json:"width"`
+		Height      int    `json:"height"`
+		Duration    int    `json:"duration"`
+		IsGif       bool   `json:"is_gif"`
+	}
+
+	Oembed struct {
+		Type         string `json:"type"`
+		ProviderName string `json:"provider_name"`
+		Title        string `json:"title"`
+		AuthorName   string `json:"author_name"`
+		ThumbnailURL string `json:"thumbnail_url"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+	}
+
+	GalleryData struct {
+		Items []GalleryItem `json:"items"`
+	}
+
+	GalleryItem struct {
+		Id      int    `json:"id"`
+		MediaId string `json:"media_id"`
+		Caption string `json:"caption"`
+	}
+
+	MediaMetadata struct {
+		Status string      `json:"status"`
+		Kind   string      `json:"e"` // Image, AnimatedImage, RedditVideo
+		Mime   string      `json:"m"`
+		Source MediaSource `json:"s"`
+	}
+
+	MediaSource struct {
+		URL    string `json:"u"`
+		GIF    string `json:"gif"`
+		MP4    string `json:"mp4"`
+		Width  int    `json:"x"`
+		Height int    `json:"y"`
+	}
+)
+
+func (l *Link) UnmarshalJSON(b []byte) (err error) {
+	type link Link
+	l.Created, err = unmarshalCreated(b, (*link)(l))
+	return err
+}
+
+// unixTime converts Reddit timestamp in seconds to UTC time, zero timestamp means unknown time
+func unixTime(seconds float64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(seconds)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkDecoding(t *testing.T) {
+	b, err := os.ReadFile("../../testdata/api/linksListing.json")
+	if err != nil {
+		t.Fatalf("Can't read testing file: %v", err)
+	}
+	listing := &Listing{}
+	err = json.Unmarshal(b, listing)
+	if err != nil {
+		t.Fatalf("Failed to decode listing: %v", err)
+	}
+	links := listing.Links()
+	assert.Len(t, links, 3)
+
+	self := links[0]
+	assert.Equal(t, "t3_151s97h", self.Name)
+	assert.Equal(t, "151s97h", self.Id)
+	assert.Equal(t, "gopher42", self.Author)
+	assert.Equal(t, "golang", self.Subreddit)
+	assert.Equal(t, "How do you update a map while iterating over it?", self.Selftext)
+	assert.Equal(t, "self.golang", self.Domain)
+	assert.Equal(t, "/r/golang/comments/151s97h/updating_map_in_range_loop/", self.Permalink)
+	assert.Equal(t, 42, self.Score)
+	assert.Equal(t, 0.87, self.UpvoteRatio)
+	assert.Equal(t, 17, self.NumComments)
+	assert.Equal(t, "help", self.LinkFlairText)
+	assert.Equal(t, "help", self.LinkFlairCSSClass)
+	assert.Equal(t, time.Date(2023, 7, 16, 17, 0, 10, 0, time.UTC), self.Created)
+	assert.True(t, self.IsSelf)
+	assert.True(t, self.Stickied)
+	assert.False(t, self.Over18)
+	assert.Nil(t, self.Media)
+
+	gallery := links[1]
+	assert.True(t, gallery.IsGallery)
+	assert.True(t, gallery.Over18)
+	assert.True(t, gallery.Spoiler)
+	assert.Equal(t, "Bear", gallery.AuthorFlairText)
+	assert.Equal(t, time.Date(2023, 7, 16, 17, 1, 40, 250000000, time.UTC), gallery.Created)
+	assert.Equal(t, []GalleryItem{{Id: 301, MediaId: "abc123", Caption: "Monday"}, {Id: 302, MediaId: "def456"}},
+		gallery.GalleryData.Items)
+	assert.Equal(t, MediaMetadata{
+		Status: "valid",
+		Kind:   "AnimatedImage",
+		Mime:   "image/gif",
+		Source: MediaSource{
+			GIF:    "https://i.redd.it/def456.gif",
+			MP4:    "https://preview.redd.it/def456.gif?format=mp4",
+			Width:  640,
+			Height: 480,
+		},
+	}, gallery.MediaMetadata["def456"])
+
+	video := links[2]
+	assert.True(t, video.IsVideo)
+	assert.Equal(t, "t3_159zzzz", video.CrosspostParent)
+	assert.Equal(t, "programming", video.CrosspostParentList[0].Subreddit)
+	assert.Equal(t, time.Date(2023, 7, 16, 15, 6, 40, 0, time.UTC), video.CrosspostParentList[0].Created)
+	assert.Equal(t, &RedditVideo{
+		FallbackURL: "https://v.redd.it/x1y2z3/DASH_720.mp4",
+		HLSURL:      "https://v.redd.it/x1y2z3/HLSPlaylist.m3u8",
+		DashURL:     "https://v.redd.it/x1y2z3/DASHPlaylist.mpd",
+		Width:       1280,
+		Height:      720,
+		Duration:    1834,
+	}, video.Media.RedditVideo)
+}
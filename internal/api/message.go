@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Message boxes
+const (
+	InboxAll            = "inbox"
+	InboxUnread         = "unread"
+	InboxSent           = "sent"
+	InboxMentions       = "mentions"
+	InboxCommentReplies = "comments"
+)
+
+// Message is a private message (t4). Comment replies and username mentions are delivered to inbox
+// as messages too, with WasComment set.
+type Message struct {
+	Id               string    `json:"id"`
+	Name             string    `json:"name"` // fullname, e.g. t4_1x2y3z
+	Author           string    `json:"author"`
+	Dest             string    `json:"dest"` // recipient username or #subreddit
+	Subject          string    `json:"subject"`
+	Body             string    `json:"body"`
+	Context          string    `json:"context"` // comment permalink for comment replies
+	Subreddit        string    `json:"subreddit"`
+	ParentId         string    `json:"parent_id"`
+	FirstMessageName string    `json:"first_message_name"`
+	Distinguished    string    `json:"distinguished"`
+	WasComment       bool      `json:"was_comment"`
+	New              bool      `json:"new"` // unread
+	Replies          *Listing  `json:"replies"`
+	Created          time.Time `json:"-"`
+}
+
+// InboxListing is a listing of message box. Comment replies and mentions are decoded as *Message
+// rather than *Comment, as inbox reports them in message form.
+type InboxListing struct {
+	Listing
+}
+
+func (m *Message) UnmarshalJSON(b []byte) (err error) {
+	type message Message
+	m.Created, err = unmarshalCreated(b, (*message)(m))
+	return err
+}
+
+func (l *InboxListing) UnmarshalJSON(b []byte) (err error) {
+	err = l.Listing.UnmarshalJSON(b)
+	if err != nil || len(l.Children) == 0 {
+		return err
+	}
+	var envelope struct {
+		Data struct {
+			Children []thingEnvelope `json:"children"`
+		} `json:"data"`
+	}
+	err = json.Unmarshal(b, &envelope)
+	if err != nil {
+		return err
+	}
+	for i, child := range envelope.Data.Children {
+		if child.Kind != KindComment || i >= len(l.Children) {
+			continue
+		}
+		message := &Message{}
+		err = json.Unmarshal(child.Data, message)
+		if err != nil {
+			return err
+		}
+		l.Children[i].Data = message
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Subreddit types
+const (
+	SubredditPublic     = "public"
+	SubredditPrivate    = "private"
+	SubredditRestricted = "restricted"
+)
+
+// Subreddit is a subreddit (t5)
+type Subreddit struct {
+	Id                string    `json:"id"`
+	Name              string    `json:"name"` // fullname, e.g. t5_2rc7j
+	DisplayName       string    `json:"display_name"`
+	Title             string    `json:"title"`
+	PublicDescription string    `json:"public_description"`
+	Description       string    `json:"description"` // sidebar markdown
+	URL               string    `json:"url"`
+	SubredditType     string    `json:"subreddit_type"`
+	Subscribers       int       `json:"subscribers"`
+	ActiveUserCount   int       `json:"active_user_count"`
+	Over18            bool      `json:"over18"`
+	Quarantine        bool      `json:"quarantine"`
+	UserIsModerator   bool      `json:"user_is_moderator"`
+	UserIsSubscriber  bool      `json:"user_is_subscriber"`
+	UserIsBanned      bool      `json:"user_is_banned"`
+	Created           time.Time `json:"-"`
+}
+
+func (s *Subreddit) UnmarshalJSON(b []byte) (err error) {
+	type subreddit Subreddit
+	s.Created, err = unmarshalCreated(b, (*subreddit)(s))
+	return err
+}
+
+// Rule is a subreddit rule
+type Rule struct {
+	Kind            string    `json:"kind"` // link, comment or all
+	ShortName       string    `json:"short_name"`
+	Description     string    `json:"description"`
+	ViolationReason string    `json:"violation_reason"`
+	Priority        int       `json:"priority"`
+	Created         time.Time `json:"-"`
+}
+
+// Moderator is a moderator of subreddit
+type Moderator struct {
+	Id             string    `json:"id"` // account fullname
+	Name           string    `json:"name"`
+	ModPermissions []string  `json:"mod_permissions"`
+	Added          time.Time `json:"-"`
+}
+
+func (r *Rule) UnmarshalJSON(b []byte) (err error) {
+	type rule Rule
+	r.Created, err = unmarshalCreated(b, (*rule)(r))
+	return err
+}
+
+func (m *Moderator) UnmarshalJSON(b []byte) (err error) {
+	type moderator Moderator
+	aux := struct {
+		*moderator
+		Date float64 `json:"date"`
+	}{moderator: (*moderator)(m)}
+	err = json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+	m.Added = unixTime(aux.Date)
+	return nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Thing kinds
+const (
+	KindComment   = "t1"
+	KindAccount   = "t2"
+	KindLink      = "t3"
+	KindMessage   = "t4"
+	KindSubreddit = "t5"
+	KindAward     = "t6"
+	KindMore      = "more"
+	KindListing   = "Listing"
+)
+
+type (
+	// Thing is Reddit object envelope. Data holds *Comment, *Account, *Link, *Message, *Subreddit,
+	// *Award, *More or *Listing depending on kind, or json.RawMessage for unknown kinds.
+	// Inbox listings are the exception, see InboxListing.
+	Thing struct {
+		Kind string
+		Data interface{}
+	}
+
+	// Listing is a page of things, which could be of different kinds, e.g. in inbox or search results
+	Listing struct {
+		After    string  `json:"after"`
+		Before   string  `json:"before"`
+		Dist     int     `json:"dist"`
+		Children []Thing `json:"children"`
+	}
+
+	thingEnvelope struct {
+		Kind string          `json:"kind"`
+		Data json.RawMessage `json:"data"`
+	}
+)
+
+func (t *Thing) UnmarshalJSON(b []byte) (err error) {
+	var envelope thingEnvelope
+	err = json.Unmarshal(b, &envelope)
+	if err != nil {
+		return err
+	}
+	t.Kind = envelope.Kind
+	switch envelope.Kind {
+	case KindComment:
+		t.Data = &Comment{}
+	case KindAccount:
+		t.Data = &Account{}
+	case KindLink:
+		t.Data = &Link{}
+	case KindMessage:
+		t.Data = &Message{}
+	case KindSubreddit:
+		t.Data = &Subreddit{}
+	case KindAward:
+		t.Data = &Award{}
+	case KindMore:
+		t.Data = &More{}
+	case KindListing:
+		listing := &Listing{}
+		t.Data = listing
+		return listing.UnmarshalJSON(b)
+	default:
+		t.Data = envelope.Data
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, t.Data)
+}
+
+// UnmarshalJSON decodes listing envelope. Empty string is decoded as empty listing,
+// as Reddit returns it instead of listing for comments without replies.
+func (l *Listing) UnmarshalJSON(b []byte) (err error) {
+	if string(b) == `""` {
+		return nil
+	}
+	var envelope thingEnvelope
+	err = json.Unmarshal(b, &envelope)
+	if err != nil {
+		return err
+	}
+	if envelope.Kind != KindListing {
+		err = fmt.Errorf("unexpected listing kind %q", envelope.Kind)
+		return err
+	}
+	type listing Listing
+	return json.Unmarshal(envelope.Data, (*listing)(l))
+}
+
+func (l *Listing) Links() []*Link {
+	return thingsOf[Link](l)
+}
+
+func (l *Listing) Comments() []*Comment {
+	return thingsOf[Comment](l)
+}
+
+func (l *Listing) Messages() []*Message {
+	return thingsOf[Message](l)
+}
+
+func (l *Listing) Accounts() []*Account {
+	return thingsOf[Account](l)
+}
+
+func (l *Listing) Subreddits() []*Subreddit {
+	return thingsOf[Subreddit](l)
+}
+
+// thingsOf returns listing children of particular type in listing order
+func thingsOf[T any](l *Listing) (things []*T) {
+	if l == nil {
+		return nil
+	}
+	for _, child := range l.Children {
+		if v, ok := child.Data.(*T); ok {
+			things = append(things, v)
+		}
+	}
+	return things
+}
+
+// unmarshalCreated decodes thing data into v and returns thing creation time
+func unmarshalCreated(b []byte, v interface{}) (created time.Time, err error) {
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		return created, err
+	}
+	var ts struct {
+		CreatedUTC float64 `json:"created_utc"`
+	}
+	err = json.Unmarshal(b, &ts)
+	if err != nil {
+		return created, err
+	}
+	return unixTime(ts.CreatedUTC), nil
+}
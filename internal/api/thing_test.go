@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMixedListingDecoding(t *testing.T) {
+	b, err := os.ReadFile("../../testdata/api/mixedListing.json")
+	if err != nil {
+		t.Fatalf("Can't read testing file: %v", err)
+	}
+	listing := &Listing{}
+	err = json.Unmarshal(b, listing)
+	if err != nil {
+		t.Fatalf("Failed to decode listing: %v", err)
+	}
+	assert.Equal(t, "t1_jsa7x2c", listing.After)
+	assert.Equal(t, "", listing.Before)
+	assert.Equal(t, 7, listing.Dist)
+
+	kinds := []string{}
+	for _, child := range listing.Children {
+		kinds = append(kinds, child.Kind)
+	}
+	assert.Equal(t, []string{KindComment, KindLink, KindMessage, KindAccount, KindSubreddit, KindMore, "t9"}, kinds)
+
+	comments := listing.Comments()
+	assert.Len(t, comments, 1)
+	assert.Equal(t, "Use a copy of the keys.", comments[0].Body)
+	assert.Equal(t, "t3_151s97h", comments[0].ParentId)
+	assert.True(t, comments[0].IsSubmitter)
+	assert.Empty(t, comments[0].Replies.Comments())
+	assert.Equal(t, time.Date(2023, 7, 16, 17, 3, 20, 0, time.UTC), comments[0].Created)
+
+	assert.Equal(t, "Updating map in range loop", listing.Links()[0].Title)
+
+	messages := listing.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "!status", messages[0].Body)
+	assert.True(t, messages[0].New)
+
+	accounts := listing.Accounts()
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, "gopher42", accounts[0].Name)
+	assert.Equal(t, 4600, accounts[0].TotalKarma)
+	assert.Equal(t, time.Date(2014, 5, 13, 16, 53, 20, 0, time.UTC), accounts[0].Created)
+
+	subreddits := listing.Subreddits()
+	assert.Len(t, subreddits, 1)
+	assert.Equal(t, "golang", subreddits[0].DisplayName)
+	assert.Equal(t, SubredditPublic, subreddits[0].SubredditType)
+
+	more, ok := listing.Children[5].Data.(*More)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"jsa8aaa", "jsa8bbb", "jsa8ccc"}, more.Children)
+
+	raw, ok := listing.Children[6].Data.(json.RawMessage)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"id": "unknown"}`, string(raw))
+}
+
+func TestNestedListingDecoding(t *testing.T) {
+	b := []byte(`{"kind": "t1", "data": {"id": "a", "replies": {"kind": "Listing", "data": {"children": [
+		{"kind": "t1", "data": {"id": "b", "replies": ""}}]}}}}`)
+	thing := &Thing{}
+	err := json.Unmarshal(b, thing)
+	if err != nil {
+		t.Fatalf("Failed to decode thing: %v", err)
+	}
+	comment := thing.Data.(*Comment)
+	assert.Equal(t, "a", comment.Id)
+	assert.Equal(t, "b", comment.Replies.Comments()[0].Id)
+
+	err = json.Unmarshal([]byte(`{"kind": "t1", "data": {}}`), &Listing{})
+	assert.NotNil(t, err)
+}
+
+func TestInboxListingDecoding(t *testing.T) {
+	b, err := os.ReadFile("../../testdata/api/inboxListing.json")
+	if err != nil {
+		t.Fatalf("Can't read testing file: %v", err)
+	}
+	listing := &InboxListing{}
+	err = json.Unmarshal(b, listing)
+	if err != nil {
+		t.Fatalf("Failed to decode listing: %v", err)
+	}
+	assert.Equal(t, "t1_jsb1aaa", listing.After)
+	assert.Empty(t, listing.Comments())
+
+	messages := listing.Messages()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "!status", messages[0].Body)
+	assert.False(t, messages[0].WasComment)
+	assert.Equal(t, KindComment, listing.Children[1].Kind)
+	assert.Equal(t, "comment reply", messages[1].Subject)
+	assert.True(t, messages[1].WasComment)
+	assert.Equal(t, "t1_jsa7x2c", messages[1].ParentId)
+	assert.Equal(t, time.Date(2023, 7, 16, 17, 6, 40, 0, time.UTC), messages[1].Created)
+
+	empty := &InboxListing{}
+	assert.Nil(t, json.Unmarshal([]byte(`""`), empty))
+	assert.Empty(t, empty.Children)
+}
@@ -5,9 +5,12 @@ import (
 	"context"
 	"dmmak/redditapi/internal/api"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -18,124 +21,597 @@ type (
 		token         authToken
 		url           string
 		requestPeriod uint
+		maxOutage     time.Duration
 		creds         Credentials
+		grant         tokenGrant
+		credsProvider CredentialsProvider // optional
+		store         TokenStore          // optional
+		revokeURL     string              // optional
+		client        *http.Client        // has timeout, so hanging auth server can't block refreshes
+		lastErr       error               // error of the last refresh
+		stopErr       *StopError          // set when maintenance gives up
 		mu            sync.RWMutex
+		refreshing    *refreshCall  // in-flight refresh, nil if there is none
+		refreshed     chan struct{} // closed and replaced when refresh completes or maintenance stops
+	}
+
+	refreshCall struct {
+		done chan struct{} // closed when refresh completes
+		err  error
 	}
 
 	authToken struct {
-		value    string
-		lifetime int
+		value        string
+		refreshToken string   // empty for grants without refresh token
+		scopes       []string // nil if server didn't report granted scopes
+		lifetime     int
+		issuedAt     time.Time
+		expiresAt    time.Time // zero if server didn't report token lifetime
 	}
 
+	// Option configures optional token poller features
+	Option func(p *authTokenPoller)
+
 	Credentials struct {
 		UserName     string
 		Password     string
 		ClientId     string
 		ClientSecret string
+		TOTPSecret   string // base32 encoded, optional
 	}
 
 	authResponse struct {
-		AccessToken string `json:"access_token"`
-		ExpiresIn   int    `json:"expires_in"`
-		Scope       string `json:"scope"`
-		TokenType   string `json:"token_type"`
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		Scope        string `json:"scope"`
+		TokenType    string `json:"token_type"`
+		Error        string `json:"error"`
 	}
 )
 
-func NewTokenPoller(url string, requestPeriod uint, creds Credentials) (tp api.AuthTokenPoller) {
-	tp = &authTokenPoller{url: url, requestPeriod: requestPeriod, creds: creds}
+const (
+	// refreshMargin is how long before the end of max outage window a new token is requested,
+	// so the token outlives retries of failed refresh and API calls keep working during an outage
+	refreshMargin = 60 * time.Second
+	// minRefreshDelay prevents busy looping on tokens with tiny lifetime
+	minRefreshDelay = time.Second
+	// tokenWaitTimeout limits how long TokenValue blocks for in-flight refresh
+	tokenWaitTimeout = 10 * time.Second
+	// authRequestTimeout limits token and revoke requests, so hanging auth server is handled as an outage
+	authRequestTimeout = 30 * time.Second
+
+	// retry delays of failed token refresh grow exponentially from retryBaseDelay up to retryMaxDelay
+	retryBaseDelay = time.Second
+	retryMaxDelay  = time.Minute
+	// defaultMaxOutage is used if max outage window isn't configured
+	defaultMaxOutage = 10 * time.Minute
+)
+
+func newAuthToken(resp *authResponse, issuedAt time.Time) (t authToken) {
+	t = authToken{
+		value:        resp.AccessToken,
+		refreshToken: resp.RefreshToken,
+		scopes:       parseScopes(resp.Scope),
+		lifetime:     resp.ExpiresIn,
+		issuedAt:     issuedAt,
+	}
+	if resp.ExpiresIn > 0 {
+		t.expiresAt = issuedAt.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return t
+}
+
+// WithTokenStore makes poller reuse stored token on start and save every new token
+func WithTokenStore(s TokenStore) Option {
+	return func(p *authTokenPoller) {
+		p.store = s
+	}
+}
+
+// WithCredentialsProvider makes poller reload credentials before every token request
+func WithCredentialsProvider(cp CredentialsProvider) Option {
+	return func(p *authTokenPoller) {
+		p.credsProvider = cp
+	}
+}
+
+// WithRevokeURL enables token revocation with Revoke
+func WithRevokeURL(url string) Option {
+	return func(p *authTokenPoller) {
+		p.revokeURL = url
+	}
+}
+
+func parseScopes(scope string) []string {
+	if scope == "" {
+		return nil
+	}
+	return strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+func (t authToken) expired(now time.Time) bool {
+	return !t.expiresAt.IsZero() && !now.Before(t.expiresAt)
+}
+
+// NewTokenPoller creates password grant token poller. maxOutage is the number of seconds
+// the poller keeps retrying failed refreshes before giving up, zero means default value.
+func NewTokenPoller(url string, requestPeriod uint, maxOutage uint, creds Credentials, opts ...Option) (tp api.AuthTokenPoller) {
+	tp = newAuthTokenPoller(url, requestPeriod, maxOutage, creds, passwordGrant{}, opts...)
+	return tp
+}
+
+// NewClientCredentialsTokenPoller creates token poller for app-only access of confidential clients,
+// username and password aren't used.
+func NewClientCredentialsTokenPoller(url string, requestPeriod uint, maxOutage uint, creds Credentials,
+	opts ...Option) (tp api.AuthTokenPoller) {
+	tp = newAuthTokenPoller(url, requestPeriod, maxOutage, creds, clientCredentialsGrant{}, opts...)
+	return tp
+}
+
+// NewInstalledClientTokenPoller creates token poller for app-only access of installed apps.
+// deviceId should be unique per device, "DO_NOT_TRACK_THIS_DEVICE" could be used as well.
+func NewInstalledClientTokenPoller(url string, requestPeriod uint, maxOutage uint, creds Credentials,
+	deviceId string, opts ...Option) (tp api.AuthTokenPoller) {
+	tp = newAuthTokenPoller(url, requestPeriod, maxOutage, creds, installedClientGrant{deviceId: deviceId}, opts...)
 	return tp
 }
 
+func newAuthTokenPoller(url string, requestPeriod uint, maxOutage uint, creds Credentials, grant tokenGrant,
+	opts ...Option) (p *authTokenPoller) {
+	outage := time.Duration(maxOutage) * time.Second
+	if outage == 0 {
+		outage = defaultMaxOutage
+	}
+	p = &authTokenPoller{
+		url:           url,
+		requestPeriod: requestPeriod,
+		maxOutage:     outage,
+		creds:         creds,
+		grant:         grant,
+		client:        &http.Client{Timeout: authRequestTimeout},
+		refreshed:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// TokenValue returns current auth token. If the token is expired and refresh is in progress,
+// it waits for the refresh to complete. Empty string is returned if there is no valid token.
 func (p *authTokenPoller) TokenValue() (value string) {
 	p.mu.RLock()
-	value = p.token.value
+	token, refreshing := p.token, p.refreshing
+	p.mu.RUnlock()
+	if !token.expired(time.Now()) {
+		return token.value
+	}
+	if refreshing == nil {
+		return ""
+	}
+	select {
+	case <-refreshing.done:
+	case <-time.After(tokenWaitTimeout):
+	}
+	p.mu.RLock()
+	token = p.token
 	p.mu.RUnlock()
-	return value
+	if token.expired(time.Now()) {
+		return ""
+	}
+	return token.value
 }
 
-func (p *authTokenPoller) Start(ctx context.Context) (exit chan struct{}, err error) {
-	exit = make(chan struct{})
-	err = p.refreshAuthToken()
-	if err != nil {
-		return nil, err
+// WaitToken returns valid auth token. If the token is expired, it waits for in-flight or scheduled refresh
+// without requesting a new token itself, so the token endpoint isn't hit beyond retry backoff.
+// StopError is returned if the poller has given up refreshing the token.
+func (p *authTokenPoller) WaitToken(ctx context.Context) (value string, err error) {
+	for {
+		p.mu.RLock()
+		token, stopErr, refreshed := p.token, p.stopErr, p.refreshed
+		p.mu.RUnlock()
+		if token.value != "" && !token.expired(time.Now()) {
+			return token.value, nil
+		}
+		if stopErr != nil {
+			return "", stopErr
+		}
+		select {
+		case <-refreshed:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// notifyRefreshed wakes up WaitToken callers, it must be called with p.mu locked
+func (p *authTokenPoller) notifyRefreshed() {
+	if p.refreshed != nil {
+		close(p.refreshed)
+	}
+	p.refreshed = make(chan struct{})
+}
+
+// AppOnly reports whether the token acts without user context
+func (p *authTokenPoller) AppOnly() bool {
+	return !p.grant.userContext()
+}
+
+func (p *authTokenPoller) Status() (s api.TokenStatus) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	s = api.TokenStatus{
+		Scopes:           p.token.scopes,
+		IssuedAt:         p.token.issuedAt,
+		ExpiresAt:        p.token.expiresAt,
+		LastRefreshError: p.lastErr,
+	}
+	return s
+}
+
+func (p *authTokenPoller) ExpiresAt() (t time.Time) {
+	p.mu.RLock()
+	t = p.token.expiresAt
+	p.mu.RUnlock()
+	return t
+}
+
+func (p *authTokenPoller) Start(ctx context.Context) (exit <-chan error, err error) {
+	p.restoreToken()
+	return p.start(ctx)
+}
+
+// start requests auth token unless restored one is fresh enough and starts token maintenance
+func (p *authTokenPoller) start(ctx context.Context) (exit <-chan error, err error) {
+	ch := make(chan error, 1)
+	p.mu.RLock()
+	token := p.token
+	p.mu.RUnlock()
+	if token.value == "" || token.expiresAt.Sub(time.Now()) <= p.refreshMargin(token) {
+		err = p.refreshAuthToken(ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 	go func() {
-		p.maintainAuthToken(ctx, exit)
-		close(exit)
+		p.maintainAuthToken(ctx, ch)
+		close(ch)
 	}()
 	log.Println("Auth token poller started")
-	return exit, nil
+	return ch, nil
+}
+
+// ForceRefresh requests new auth token because staleToken was rejected by API. Nothing is requested
+// if the token has been already replaced by a valid one, and concurrent calls share single refresh.
+// Rejected credentials are reported as StopError, as the token can't be refreshed anymore.
+func (p *authTokenPoller) ForceRefresh(ctx context.Context, staleToken string) (err error) {
+	result := make(chan error, 1)
+	go func() {
+		p.mu.Lock()
+		if staleToken != "" && p.token.value != staleToken && !p.token.expired(time.Now()) {
+			p.mu.Unlock()
+			result <- nil
+			return
+		}
+		// refresh is shared by concurrent callers, so it isn't bound to the context of any of them
+		err := p.refreshLocked(context.Background())
+		var authErr *AuthError
+		if errors.As(err, &authErr) && authErr.Terminal() {
+			err = &StopError{Reason: ErrCredentialsRejected, LastErr: err}
+		}
+		result <- err
+	}()
+	select {
+	case err = <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *authTokenPoller) refreshAuthToken(ctx context.Context) (err error) {
+	p.mu.Lock()
+	return p.refreshLocked(ctx)
+}
+
+// refreshLocked requests new auth token or joins the refresh which is already in progress.
+// It must be called with p.mu locked and releases the lock.
+func (p *authTokenPoller) refreshLocked(ctx context.Context) (err error) {
+	if call := p.refreshing; call != nil {
+		p.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	p.refreshing = call
+	p.mu.Unlock()
+
+	issuedAt := time.Now()
+	var authResp *authResponse
+	err = p.reloadCredentials()
+	if err == nil {
+		authResp, err = p.requestAuthToken(ctx)
+	}
+	p.mu.Lock()
+	if err == nil {
+		p.grant.tokenIssued(authResp)
+		token := newAuthToken(authResp, issuedAt)
+		if token.refreshToken == "" {
+			token.refreshToken = p.token.refreshToken
+		}
+		p.token = token
+	}
+	p.lastErr = err
+	p.refreshing = nil
+	p.notifyRefreshed()
+	stored := p.storedToken()
+	p.mu.Unlock()
+	if err == nil && p.store != nil {
+		if saveErr := p.store.Save(stored); saveErr != nil {
+			log.Printf("Error while saving auth token: %v\n", saveErr)
+		}
+	}
+	call.err = err
+	close(call.done)
+	return err
 }
 
-func (p *authTokenPoller) refreshAuthToken() (err error) {
+// reloadCredentials picks up rotated credentials from credentials provider if it's configured
+func (p *authTokenPoller) reloadCredentials() (err error) {
+	if p.credsProvider == nil {
+		return nil
+	}
+	creds, err := p.credsProvider.Credentials()
+	if err != nil {
+		err = fmt.Errorf("couldn't load credentials: %w", err)
+		return err
+	}
 	p.mu.Lock()
-	defer p.mu.Unlock()
-	authResp, err := p.requestAuthToken()
+	p.creds = creds
+	p.mu.Unlock()
+	return nil
+}
+
+// restoreToken loads token saved by previous run, if it was issued for the same credentials
+func (p *authTokenPoller) restoreToken() {
+	if p.store == nil {
+		return
+	}
+	stored, err := p.store.Load()
+	if err != nil {
+		log.Printf("Error while loading stored auth token: %v\n", err)
+		return
+	}
+	if stored == nil || stored.ClientId != p.creds.ClientId || stored.UserName != p.creds.UserName {
+		return
+	}
+	// let the grant pick up refresh token as if it was just issued
+	p.grant.tokenIssued(&authResponse{RefreshToken: stored.RefreshToken})
+	p.mu.Lock()
+	p.token = authToken{
+		value:        stored.AccessToken,
+		refreshToken: stored.RefreshToken,
+		scopes:       parseScopes(stored.Scope),
+		lifetime:     stored.Lifetime,
+		issuedAt:     stored.IssuedAt,
+		expiresAt:    stored.ExpiresAt,
+	}
+	p.mu.Unlock()
+	log.Println("Stored auth token loaded")
+}
+
+// storedToken must be called with p.mu locked
+func (p *authTokenPoller) storedToken() (t *StoredToken) {
+	t = &StoredToken{
+		ClientId:     p.creds.ClientId,
+		UserName:     p.creds.UserName,
+		AccessToken:  p.token.value,
+		RefreshToken: p.token.refreshToken,
+		Scope:        strings.Join(p.token.scopes, " "),
+		Lifetime:     p.token.lifetime,
+		IssuedAt:     p.token.issuedAt,
+		ExpiresAt:    p.token.expiresAt,
+	}
+	return t
+}
+
+// Revoke invalidates current token at auth server. It does nothing if revoke URL isn't configured.
+// If token store is configured, stored refresh token is kept valid for the next run and only access token
+// is revoked, and token without refresh token isn't revoked at all.
+func (p *authTokenPoller) Revoke(ctx context.Context) (err error) {
+	if p.revokeURL == "" {
+		return nil
+	}
+	p.mu.RLock()
+	token := p.token
+	stored := p.storedToken()
+	p.mu.RUnlock()
+	if p.store != nil && token.refreshToken == "" {
+		log.Println("Auth token is kept in token store, revocation skipped")
+		return nil
+	}
+	// revoking refresh token invalidates access tokens issued with it as well
+	value, hint := token.value, "access_token"
+	if token.refreshToken != "" && p.store == nil {
+		value, hint = token.refreshToken, "refresh_token"
+	}
+	if value == "" {
+		return nil
+	}
+	params := url.Values{}
+	params.Set("token", value)
+	params.Set("token_type_hint", hint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.revokeURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.creds.ClientId, p.creds.ClientSecret)
+	resp, err := p.client.Do(req)
 	if err != nil {
+		err = fmt.Errorf("error while revoking auth token: %w", err)
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		err = fmt.Errorf("invalid revoke token response code: %d", resp.StatusCode)
 		return err
 	}
-	p.token.value = authResp.AccessToken
-	p.token.lifetime = authResp.ExpiresIn
+	if p.store != nil {
+		// revoked access token is dropped, so the next run starts with refresh
+		stored.AccessToken = ""
+		err = p.store.Save(stored)
+		if err != nil {
+			return err
+		}
+	}
+	log.Println("Auth token revoked")
 	return nil
 }
 
-func (p *authTokenPoller) maintainAuthToken(ctx context.Context, exit chan<- struct{}) {
+// refreshDelay computes time until next token refresh based on token lifetime.
+// Configured request period is used as an upper bound, and minRefreshDelay as a lower one.
+func (p *authTokenPoller) refreshDelay(now time.Time) (d time.Duration) {
+	d = time.Duration(p.requestPeriod) * time.Second
+	p.mu.RLock()
+	token := p.token
+	p.mu.RUnlock()
+	if !token.expiresAt.IsZero() {
+		untilRefresh := token.expiresAt.Sub(now) - p.refreshMargin(token)
+		if d <= 0 || untilRefresh < d {
+			d = untilRefresh
+		}
+	}
+	if d < minRefreshDelay {
+		d = minRefreshDelay
+	}
+	return d
+}
+
+// refreshMargin computes how long before token expiration a new token is requested.
+// Refresh starts at the middle of token lifetime at the latest.
+func (p *authTokenPoller) refreshMargin(token authToken) (margin time.Duration) {
+	margin = p.maxOutage + refreshMargin
+	if lifetime := time.Duration(token.lifetime) * time.Second; lifetime < 2*margin {
+		margin = lifetime / 2
+	}
+	return margin
+}
+
+func (p *authTokenPoller) maintainAuthToken(ctx context.Context, exit chan<- error) {
+	var outageStart time.Time
+	attempt := 0
+	delay := p.refreshDelay(time.Now())
 	for {
 		select {
-		case <-time.After(time.Duration(p.requestPeriod) * time.Second):
-			err := p.refreshAuthToken()
-			if err != nil {
-				log.Printf("Error while refreshing auth token: %v\n", err)
-				exit <- struct{}{}
+		case <-time.After(delay):
+			err := p.refreshAuthToken(ctx)
+			if err == nil {
+				if attempt > 0 {
+					log.Printf("Auth token refreshed after %v failed attempts\n", attempt)
+				}
+				outageStart, attempt = time.Time{}, 0
+				delay = p.refreshDelay(time.Now())
+				continue
+			}
+			if outageStart.IsZero() {
+				outageStart = time.Now()
+			}
+			if stopErr := p.checkRefreshError(err, time.Since(outageStart)); stopErr != nil {
+				log.Printf("Error while refreshing auth token, giving up: %v\n", err)
+				p.mu.Lock()
+				p.stopErr = stopErr
+				p.notifyRefreshed()
+				p.mu.Unlock()
+				exit <- stopErr
 				return
 			}
+			delay = retryDelay(attempt)
+			attempt++
+			log.Printf("Error while refreshing auth token, retry in %v: %v\n", delay, err)
 		case <-ctx.Done():
-			exit <- struct{}{}
 			return
 		}
 	}
 }
 
-func (p *authTokenPoller) makeAuthRequest() (req *http.Request, err error) {
-	body := p.makeAuthRequestBody()
-	req, err = http.NewRequest(http.MethodPost, p.url, body)
+// checkRefreshError returns non-nil error if token refresh shouldn't be retried anymore
+func (p *authTokenPoller) checkRefreshError(err error, outage time.Duration) (stopErr *StopError) {
+	var authErr *AuthError
+	if errors.As(err, &authErr) && authErr.Terminal() {
+		return &StopError{Reason: ErrCredentialsRejected, LastErr: err}
+	}
+	if outage >= p.maxOutage {
+		return &StopError{Reason: ErrOutageExceeded, LastErr: err}
+	}
+	return nil
+}
+
+// retryDelay computes exponential backoff delay with jitter for given retry attempt
+func retryDelay(attempt int) (d time.Duration) {
+	d = retryMaxDelay
+	if attempt < 16 && retryBaseDelay<<attempt < retryMaxDelay {
+		d = retryBaseDelay << attempt
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (p *authTokenPoller) makeAuthRequest(ctx context.Context) (req *http.Request, err error) {
+	body, err := p.makeAuthRequestBody()
+	if err != nil {
+		return nil, err
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, p.url, body)
 	if err != nil {
 		return req, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.SetBasicAuth(p.creds.ClientId, p.creds.ClientSecret)
 	return req, nil
 }
 
-func (p *authTokenPoller) makeAuthRequestBody() (body *bytes.Buffer) {
-	var authParams strings.Builder
-	authParams.WriteString("grant_type=password")
-	authParams.WriteString("&username=")
-	authParams.WriteString(p.creds.UserName)
-	authParams.WriteString("&password=")
-	authParams.WriteString(p.creds.Password)
-	body = bytes.NewBuffer([]byte(authParams.String()))
-	return body
+func (p *authTokenPoller) makeAuthRequestBody() (body *bytes.Buffer, err error) {
+	params, err := p.grant.requestBody(p.creds)
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.NewBufferString(params)
+	return body, nil
 }
 
-func (p *authTokenPoller) requestAuthToken() (authResp *authResponse, err error) {
+func (p *authTokenPoller) requestAuthToken(ctx context.Context) (authResp *authResponse, err error) {
 	authResp = &authResponse{}
-	req, err := p.makeAuthRequest()
+	req, err := p.makeAuthRequest(ctx)
 	if err != nil {
 		return authResp, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := p.client.Do(req)
 	if err != nil {
-		return authResp, err
+		err = &AuthError{Err: err}
+		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("invalid auth token response code: %d", resp.StatusCode)
+		err = &AuthError{
+			StatusCode: resp.StatusCode,
+			Err:        fmt.Errorf("invalid auth token response code: %d", resp.StatusCode),
+		}
 		return nil, err
 	}
 	err = json.NewDecoder(resp.Body).Decode(authResp)
 	if err != nil {
-		err = fmt.Errorf("error decoding auth response: %w", err)
+		err = &AuthError{StatusCode: resp.StatusCode, Err: fmt.Errorf("error decoding auth response: %w", err)}
+		return nil, err
+	}
+	if authResp.Error != "" {
+		err = &AuthError{
+			StatusCode: resp.StatusCode,
+			Code:       authResp.Error,
+			Err:        fmt.Errorf("auth token request failed: %v", authResp.Error),
+		}
 		return nil, err
 	}
 	return authResp, nil
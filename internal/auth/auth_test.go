@@ -3,18 +3,28 @@ package auth
 import (
 	"bytes"
 	"context"
+	"dmmak/redditapi/internal/api"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMakeAuthRequestBody(t *testing.T) {
 	tp := &authTokenPoller{
+		grant:         passwordGrant{},
 		url:           "blank",
 		requestPeriod: 100,
 		creds: Credentials{
@@ -22,16 +32,95 @@ func TestMakeAuthRequestBody(t *testing.T) {
 			Password: "Doe",
 		},
 	}
-	expected := "grant_type=password&username=Jhon&password=Doe"
-	actual, err := ioutil.ReadAll(tp.makeAuthRequestBody())
+	expected := "grant_type=password&password=Doe&username=Jhon"
+	body, err := tp.makeAuthRequestBody()
+	if err != nil {
+		t.Fatalf("Failed to make request body, err: %v", err)
+	}
+	actual, err := ioutil.ReadAll(body)
 	if err != nil {
 		t.Fatalf("Failed to read request body, err: %v", err)
 	}
 	assert.Equal(t, expected, string(actual))
 }
 
+func TestPasswordGrantRequestBody(t *testing.T) {
+	cases := []struct {
+		name     string
+		creds    Credentials
+		expected url.Values
+	}{
+		{
+			"specialCharacters",
+			Credentials{UserName: "Jhon", Password: "a&b=c d+"},
+			url.Values{"grant_type": {"password"}, "username": {"Jhon"}, "password": {"a&b=c d+"}},
+		},
+		{
+			"totp",
+			Credentials{UserName: "Jhon", Password: "Doe", TOTPSecret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"},
+			nil,
+		},
+	}
+	for _, c := range cases {
+		body, err := passwordGrant{}.requestBody(c.creds)
+		assert.Nil(t, err, c.name)
+		actual, err := url.ParseQuery(body)
+		assert.Nil(t, err, c.name)
+		if c.expected != nil {
+			assert.Equal(t, c.expected, actual, c.name)
+			continue
+		}
+		assert.Regexp(t, `^Doe:\d{6}$`, actual.Get("password"), c.name)
+	}
+
+	_, err := passwordGrant{}.requestBody(Credentials{Password: "Doe", TOTPSecret: "not base32!"})
+	assert.NotNil(t, err)
+}
+
+func TestAppOnlyRequestBody(t *testing.T) {
+	cases := []struct {
+		name            string
+		tp              *authTokenPoller
+		expectedBody    string
+		expectedAppOnly bool
+	}{
+		{
+			"password",
+			NewTokenPoller("blank", 100, 0, Credentials{UserName: "Jhon", Password: "Doe"}).(*authTokenPoller),
+			"grant_type=password&password=Doe&username=Jhon",
+			false,
+		},
+		{
+			"clientCredentials",
+			NewClientCredentialsTokenPoller("blank", 100, 0, Credentials{ClientId: "clientId"}).(*authTokenPoller),
+			"grant_type=client_credentials",
+			true,
+		},
+		{
+			"installedClient",
+			NewInstalledClientTokenPoller("blank", 100, 0, Credentials{ClientId: "clientId"},
+				"DO_NOT_TRACK_THIS_DEVICE").(*authTokenPoller),
+			"device_id=DO_NOT_TRACK_THIS_DEVICE&grant_type=https%3A%2F%2Foauth.reddit.com%2Fgrants%2Finstalled_client",
+			true,
+		},
+	}
+	for _, c := range cases {
+		body, err := c.tp.makeAuthRequestBody()
+		if err != nil {
+			t.Fatalf("Failed to make request body, err: %v", err)
+		}
+		actual, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatalf("Failed to read request body, err: %v", err)
+		}
+		assert.Equal(t, c.expectedBody, string(actual), c.name)
+		assert.Equal(t, c.expectedAppOnly, c.tp.AppOnly(), c.name)
+	}
+}
+
 func TestMakeAuthRequest(t *testing.T) {
 	tp := &authTokenPoller{
+		grant:         passwordGrant{},
 		url:           "http://reddit.com",
 		requestPeriod: 100,
 		creds: Credentials{
@@ -41,13 +130,16 @@ func TestMakeAuthRequest(t *testing.T) {
 			ClientSecret: "clientSecret",
 		},
 	}
-	expectedBody := tp.makeAuthRequestBody()
+	expectedBody, err := tp.makeAuthRequestBody()
+	if err != nil {
+		t.Fatalf("Failed to make expected request body, err: %v", err)
+	}
 	expected, err := http.NewRequest(http.MethodPost, "http://reddit.com", expectedBody)
 	if err != nil {
 		t.Fatalf("Failed to make expected request, err: %v", err)
 	}
 	expected.SetBasicAuth("clientId", "clientSecret")
-	actual, err := tp.makeAuthRequest()
+	actual, err := tp.makeAuthRequest(context.Background())
 	if err != nil {
 		t.Fatalf("Failed to make actual request, err: %v", err)
 	}
@@ -78,10 +170,10 @@ func TestRequestAuthToken(t *testing.T) {
 		}
 		ts := httptest.NewServer(http.HandlerFunc(handler))
 
-		poller := &authTokenPoller{url: ts.URL}
+		poller := &authTokenPoller{url: ts.URL, grant: passwordGrant{}, client: &http.Client{}}
 		expectedResponse := &authResponse{}
 		json.NewDecoder(bytes.NewBuffer(c.body)).Decode(expectedResponse)
-		actualResponse, actualErr := poller.requestAuthToken()
+		actualResponse, actualErr := poller.requestAuthToken(context.Background())
 
 		if c.name == "success" {
 			if actualErr != nil {
@@ -92,7 +184,10 @@ func TestRequestAuthToken(t *testing.T) {
 		}
 
 		if c.name == "failure" {
-			expectedErr := fmt.Errorf("invalid auth token response code: %v", http.StatusUnauthorized)
+			expectedErr := &AuthError{
+				StatusCode: http.StatusUnauthorized,
+				Err:        fmt.Errorf("invalid auth token response code: %v", http.StatusUnauthorized),
+			}
 			assert.Equal(t, expectedErr, actualErr)
 		}
 
@@ -121,7 +216,7 @@ func TestAuthPollerStart(t *testing.T) {
 		}
 		ts := httptest.NewServer(http.HandlerFunc(handler))
 
-		poller := &authTokenPoller{url: ts.URL, requestPeriod: 180}
+		poller := &authTokenPoller{url: ts.URL, grant: passwordGrant{}, client: &http.Client{}, requestPeriod: 180}
 
 		ctx, cancel := context.WithCancel(context.Background())
 		actualChan, actualErr := poller.Start(ctx)
@@ -139,3 +234,568 @@ func TestAuthPollerStart(t *testing.T) {
 		ts.Close()
 	}
 }
+
+func TestRefreshDelay(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name          string
+		requestPeriod uint
+		maxOutage     time.Duration
+		token         authToken
+		expected      time.Duration
+	}{
+		{
+			"unknownLifetime",
+			180,
+			0,
+			authToken{value: "someValue"},
+			180 * time.Second,
+		},
+		{
+			"unknownLifetimeWithoutPeriod",
+			0,
+			0,
+			authToken{value: "someValue"},
+			minRefreshDelay,
+		},
+		{
+			"lifetimeShorterThanPeriod",
+			7200,
+			0,
+			authToken{value: "someValue", lifetime: 3600, expiresAt: now.Add(3600 * time.Second)},
+			3600*time.Second - refreshMargin,
+		},
+		{
+			"outageWindowReserved",
+			7200,
+			10 * time.Minute,
+			authToken{value: "someValue", lifetime: 3600, expiresAt: now.Add(3600 * time.Second)},
+			3600*time.Second - refreshMargin - 10*time.Minute,
+		},
+		{
+			"periodShorterThanLifetime",
+			180,
+			0,
+			authToken{value: "someValue", lifetime: 3600, expiresAt: now.Add(3600 * time.Second)},
+			180 * time.Second,
+		},
+		{
+			"shortLifetime",
+			180,
+			10 * time.Minute,
+			authToken{value: "someValue", lifetime: 60, expiresAt: now.Add(60 * time.Second)},
+			30 * time.Second,
+		},
+		{
+			"expiredToken",
+			180,
+			0,
+			authToken{value: "someValue", lifetime: 3600, expiresAt: now.Add(-time.Second)},
+			minRefreshDelay,
+		},
+	}
+	for _, c := range cases {
+		poller := &authTokenPoller{requestPeriod: c.requestPeriod, maxOutage: c.maxOutage, token: c.token}
+		assert.Equal(t, c.expected, poller.refreshDelay(now), c.name)
+	}
+}
+
+func TestTokenValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		token    authToken
+		expected string
+	}{
+		{
+			"unknownLifetime",
+			authToken{value: "someValue"},
+			"someValue",
+		},
+		{
+			"validToken",
+			authToken{value: "someValue", lifetime: 3600, expiresAt: time.Now().Add(time.Hour)},
+			"someValue",
+		},
+		{
+			"expiredToken",
+			authToken{value: "someValue", lifetime: 3600, expiresAt: time.Now().Add(-time.Second)},
+			"",
+		},
+	}
+	for _, c := range cases {
+		poller := &authTokenPoller{token: c.token}
+		assert.Equal(t, c.expected, poller.TokenValue(), c.name)
+	}
+}
+
+func TestTokenValueWaitsForRefresh(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"newValue","expires_in":3600}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := &authTokenPoller{
+		grant:  passwordGrant{},
+		url:    ts.URL,
+		client: &http.Client{},
+		token:  authToken{value: "oldValue", lifetime: 3600, expiresAt: time.Now().Add(-time.Second)},
+	}
+	go poller.refreshAuthToken(context.Background())
+	assert.Eventually(t, func() bool {
+		poller.mu.RLock()
+		defer poller.mu.RUnlock()
+		return poller.refreshing != nil
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, "newValue", poller.TokenValue())
+	assert.False(t, poller.ExpiresAt().IsZero())
+}
+
+func TestCheckRefreshError(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		outage   time.Duration
+		expected error
+	}{
+		{
+			"serverError",
+			&AuthError{StatusCode: http.StatusBadGateway, Err: errors.New("bad gateway")},
+			time.Minute,
+			nil,
+		},
+		{
+			"networkError",
+			&AuthError{Err: errors.New("no such host")},
+			time.Minute,
+			nil,
+		},
+		{
+			"tooManyRequests",
+			&AuthError{StatusCode: http.StatusTooManyRequests, Err: errors.New("too many requests")},
+			time.Minute,
+			nil,
+		},
+		{
+			"unauthorized",
+			&AuthError{StatusCode: http.StatusUnauthorized, Err: errors.New("unauthorized")},
+			0,
+			ErrCredentialsRejected,
+		},
+		{
+			"invalidGrant",
+			&AuthError{StatusCode: http.StatusOK, Code: "invalid_grant", Err: errors.New("invalid_grant")},
+			0,
+			ErrCredentialsRejected,
+		},
+		{
+			"outageExceeded",
+			&AuthError{StatusCode: http.StatusBadGateway, Err: errors.New("bad gateway")},
+			10 * time.Minute,
+			ErrOutageExceeded,
+		},
+	}
+	for _, c := range cases {
+		poller := &authTokenPoller{maxOutage: 10 * time.Minute}
+		actual := poller.checkRefreshError(c.err, c.outage)
+		if c.expected == nil {
+			assert.Nil(t, actual, c.name)
+			continue
+		}
+		assert.ErrorIs(t, actual, c.expected, c.name)
+		assert.ErrorIs(t, actual, c.err, c.name)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		expectedMax := retryMaxDelay
+		if attempt < 6 {
+			expectedMax = retryBaseDelay << attempt
+		}
+		actual := retryDelay(attempt)
+		assert.GreaterOrEqual(t, actual, expectedMax/2)
+		assert.LessOrEqual(t, actual, expectedMax)
+	}
+}
+
+func TestMaintainAuthTokenStopsOnRejectedCredentials(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := &authTokenPoller{
+		grant:     passwordGrant{},
+		url:       ts.URL,
+		client:    &http.Client{},
+		maxOutage: time.Minute,
+		token:     authToken{value: "someValue", lifetime: 1, expiresAt: time.Now()},
+	}
+	exit := make(chan error, 1)
+	poller.maintainAuthToken(context.Background(), exit)
+
+	err := <-exit
+	var authErr *AuthError
+	assert.ErrorIs(t, err, ErrCredentialsRejected)
+	assert.ErrorAs(t, err, &authErr)
+	assert.Equal(t, "invalid_grant", authErr.Code)
+}
+
+func TestMaintainAuthTokenHangingServer(t *testing.T) {
+	done := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+	defer close(done)
+
+	poller := &authTokenPoller{
+		grant:     passwordGrant{},
+		url:       ts.URL,
+		client:    &http.Client{Timeout: 50 * time.Millisecond},
+		maxOutage: time.Nanosecond,
+		token:     authToken{value: "someValue", lifetime: 1, expiresAt: time.Now()},
+	}
+	exit := make(chan error, 1)
+	go poller.maintainAuthToken(context.Background(), exit)
+
+	select {
+	case err := <-exit:
+		var authErr *AuthError
+		assert.ErrorIs(t, err, ErrOutageExceeded)
+		assert.ErrorAs(t, err, &authErr)
+		assert.Equal(t, 0, authErr.StatusCode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Token maintenance is blocked by hanging auth server")
+	}
+
+	// shutdown cancels hanging request
+	poller.client = &http.Client{}
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- poller.refreshAuthToken(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-result:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Token request isn't cancelled")
+	}
+}
+
+func TestTokenServedDuringOutage(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"someValue","expires_in":4}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := newAuthTokenPoller(ts.URL, 180, 600, Credentials{}, passwordGrant{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := poller.Start(ctx)
+	assert.Nil(t, err)
+
+	// refresh starts early enough to be retried while the token is still valid
+	assert.Eventually(t, func() bool {
+		return poller.Status().LastRefreshError != nil
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "someValue", poller.TokenValue())
+}
+
+func TestForceRefresh(t *testing.T) {
+	var requests int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"newValue","expires_in":3600}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := &authTokenPoller{
+		grant:  passwordGrant{},
+		url:    ts.URL,
+		client: &http.Client{},
+		token:  authToken{value: "oldValue", lifetime: 3600, expiresAt: time.Now().Add(time.Hour)},
+	}
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, poller.ForceRefresh(context.Background(), "oldValue"))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, "newValue", poller.TokenValue())
+
+	// token has been already replaced, so nothing is requested
+	assert.Nil(t, poller.ForceRefresh(context.Background(), "oldValue"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// token expired while refresh was backing off, so there is no token to compare with
+	poller.token = authToken{value: "newValue", lifetime: 3600, expiresAt: time.Now().Add(-time.Minute)}
+	assert.Equal(t, "", poller.TokenValue())
+	assert.Nil(t, poller.ForceRefresh(context.Background(), ""))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, "newValue", poller.TokenValue())
+
+	// expired token is refreshed even if it differs from the stale one
+	poller.token = authToken{value: "otherValue", lifetime: 3600, expiresAt: time.Now().Add(-time.Minute)}
+	assert.Nil(t, poller.ForceRefresh(context.Background(), "oldValue"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestWaitToken(t *testing.T) {
+	var requests int32
+	status := http.StatusOK
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"access_token":"newValue","expires_in":3600}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := newAuthTokenPoller(ts.URL, 180, 0, Credentials{}, passwordGrant{})
+	poller.token = authToken{value: "oldValue", lifetime: 3600, expiresAt: time.Now().Add(-time.Second)}
+
+	// scheduled refresh is awaited, nothing is requested by WaitToken itself
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		poller.refreshAuthToken(context.Background())
+	}()
+	value, err := poller.WaitToken(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "newValue", value)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// poller gave up, so there is nothing to wait for
+	status = http.StatusUnauthorized
+	poller.token = authToken{value: "newValue", lifetime: 1, expiresAt: time.Now()}
+	exit := make(chan error, 1)
+	go poller.maintainAuthToken(context.Background(), exit)
+	_, err = poller.WaitToken(context.Background())
+	assert.ErrorIs(t, err, ErrCredentialsRejected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	poller.stopErr = nil
+	_, err = poller.WaitToken(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestForceRefreshRejectedCredentials(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"error":"invalid_grant"}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := newAuthTokenPoller(ts.URL, 180, 0, Credentials{}, passwordGrant{})
+	poller.token = authToken{value: "oldValue"}
+
+	err := poller.ForceRefresh(context.Background(), "oldValue")
+	var stopErr *StopError
+	assert.ErrorAs(t, err, &stopErr)
+	assert.ErrorIs(t, err, ErrCredentialsRejected)
+}
+
+func TestAuthPollerStartWithTokenStore(t *testing.T) {
+	cases := []struct {
+		name             string
+		stored           *StoredToken
+		expectedToken    string
+		expectedRequests int32
+	}{
+		{
+			"validToken",
+			&StoredToken{ClientId: "clientId", UserName: "Jhon", AccessToken: "storedValue",
+				Lifetime: 3600, ExpiresAt: time.Now().Add(time.Hour)},
+			"storedValue",
+			0,
+		},
+		{
+			"expiredToken",
+			&StoredToken{ClientId: "clientId", UserName: "Jhon", AccessToken: "storedValue",
+				Lifetime: 3600, ExpiresAt: time.Now().Add(-time.Hour)},
+			"newValue",
+			1,
+		},
+		{
+			"otherAccount",
+			&StoredToken{ClientId: "clientId", UserName: "Jane", AccessToken: "storedValue",
+				Lifetime: 3600, ExpiresAt: time.Now().Add(time.Hour)},
+			"newValue",
+			1,
+		},
+	}
+	for _, c := range cases {
+		var requests int32
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"access_token":"newValue","expires_in":3600}`))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"), "")
+		store.Save(c.stored)
+		tp := NewTokenPoller(ts.URL, 180, 0, Credentials{ClientId: "clientId", UserName: "Jhon"},
+			WithTokenStore(store))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err := tp.Start(ctx)
+		assert.Nil(t, err, c.name)
+		assert.Equal(t, c.expectedToken, tp.TokenValue(), c.name)
+		assert.Equal(t, c.expectedRequests, atomic.LoadInt32(&requests), c.name)
+
+		actual, _ := store.Load()
+		assert.Equal(t, c.expectedToken, actual.AccessToken, c.name)
+
+		cancel()
+		ts.Close()
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	cases := []struct {
+		name          string
+		token         authToken
+		withStore     bool
+		expectedToken string
+		expectedHint  string
+		expectedStore *StoredToken
+	}{
+		{
+			"accessToken",
+			authToken{value: "someValue"},
+			false,
+			"someValue",
+			"access_token",
+			nil,
+		},
+		{
+			"refreshToken",
+			authToken{value: "someValue", refreshToken: "refreshValue"},
+			false,
+			"refreshValue",
+			"refresh_token",
+			nil,
+		},
+		{
+			"storedRefreshToken",
+			authToken{value: "someValue", refreshToken: "refreshValue"},
+			true,
+			"someValue",
+			"access_token",
+			&StoredToken{RefreshToken: "refreshValue"},
+		},
+		{
+			"storedAccessToken",
+			authToken{value: "someValue"},
+			true,
+			"",
+			"",
+			&StoredToken{AccessToken: "someValue"},
+		},
+	}
+	for _, c := range cases {
+		var form url.Values
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm
+			w.WriteHeader(http.StatusNoContent)
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		poller := &authTokenPoller{token: c.token, revokeURL: ts.URL, client: &http.Client{}}
+		path := filepath.Join(t.TempDir(), "token.json")
+		store := NewFileTokenStore(path, "")
+		if c.withStore {
+			poller.store = store
+			store.Save(poller.storedToken())
+		}
+
+		err := poller.Revoke(context.Background())
+		assert.Nil(t, err, c.name)
+		assert.Equal(t, c.expectedToken, form.Get("token"), c.name)
+		assert.Equal(t, c.expectedHint, form.Get("token_type_hint"), c.name)
+		actual, _ := store.Load()
+		assert.Equal(t, c.expectedStore, actual, c.name)
+
+		ts.Close()
+	}
+}
+
+func TestRefreshReloadsCredentials(t *testing.T) {
+	var actualUser string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		actualUser = r.PostForm.Get("username")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"someValue","expires_in":3600}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "secrets")
+	os.WriteFile(path, []byte("USERNAME=Jhon\nPASSWORD=Doe\n"), 0600)
+	poller := newAuthTokenPoller(ts.URL, 180, 0, Credentials{UserName: "Jhon"}, passwordGrant{},
+		WithCredentialsProvider(NewSecretsFileCredentials(path)))
+
+	assert.Nil(t, poller.refreshAuthToken(context.Background()))
+	assert.Equal(t, "Jhon", actualUser)
+
+	// rotated secrets are picked up on the next refresh
+	os.WriteFile(path, []byte("USERNAME=Jane\nPASSWORD=Doe\n"), 0600)
+	assert.Nil(t, poller.refreshAuthToken(context.Background()))
+	assert.Equal(t, "Jane", actualUser)
+}
+
+func TestStatus(t *testing.T) {
+	status := http.StatusOK
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"access_token":"someValue","expires_in":3600,"scope":"identity read save"}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	poller := newAuthTokenPoller(ts.URL, 180, 0, Credentials{}, passwordGrant{})
+	assert.Nil(t, poller.refreshAuthToken(context.Background()))
+
+	actual := poller.Status()
+	assert.Equal(t, []string{"identity", "read", "save"}, actual.Scopes)
+	assert.Equal(t, time.Hour, actual.ExpiresAt.Sub(actual.IssuedAt))
+	assert.Nil(t, actual.LastRefreshError)
+	assert.True(t, actual.Allows(api.ScopeSave))
+	assert.False(t, actual.Allows(api.ScopeSubmit))
+
+	// failed refresh keeps the token, but reports the error
+	status = http.StatusInternalServerError
+	assert.NotNil(t, poller.refreshAuthToken(context.Background()))
+	actual = poller.Status()
+	assert.Equal(t, []string{"identity", "read", "save"}, actual.Scopes)
+	assert.NotNil(t, actual.LastRefreshError)
+}
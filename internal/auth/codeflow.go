@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"dmmak/redditapi/internal/api"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type (
+	codeFlowTokenPoller struct {
+		*authTokenPoller
+		grant        *codeGrant
+		authorizeURL string
+		scopes       []string
+		newState     func() (string, error)
+	}
+
+	// CodeFlowParams configures OAuth2 authorization code flow
+	CodeFlowParams struct {
+		AuthorizeURL string // e.g. https://www.reddit.com/api/v1/authorize
+		RedirectURI  string // must match the app settings, callback listener is started on its host
+		Scopes       []string
+	}
+
+	callbackResult struct {
+		code string
+		err  error
+	}
+)
+
+// NewCodeFlowTokenPoller creates token poller which asks user to authorize the app in browser on start,
+// exchanges received authorization code for tokens and keeps access token fresh with the refresh token.
+func NewCodeFlowTokenPoller(url string, requestPeriod uint, maxOutage uint, creds Credentials,
+	params CodeFlowParams, opts ...Option) (tp api.AuthTokenPoller) {
+	grant := &codeGrant{redirectURI: params.RedirectURI}
+	tp = &codeFlowTokenPoller{
+		authTokenPoller: newAuthTokenPoller(url, requestPeriod, maxOutage, creds, grant, opts...),
+		grant:           grant,
+		authorizeURL:    params.AuthorizeURL,
+		scopes:          params.Scopes,
+		newState:        randomState,
+	}
+	return tp
+}
+
+// Start skips authorization if refresh token has been restored from token store.
+// If the restored refresh token is rejected, e.g. it has been revoked, the user is asked to authorize again.
+func (p *codeFlowTokenPoller) Start(ctx context.Context) (exit <-chan error, err error) {
+	p.restoreToken()
+	if p.grant.refreshToken != "" {
+		exit, err = p.start(ctx)
+		var authErr *AuthError
+		if !errors.As(err, &authErr) || !authErr.Terminal() {
+			return exit, err
+		}
+		log.Printf("Stored refresh token rejected, authorization is required: %v\n", err)
+		p.dropToken()
+	}
+	code, err := p.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.grant.code = code
+	return p.start(ctx)
+}
+
+// dropToken forgets rejected token along with its stored copy
+func (p *codeFlowTokenPoller) dropToken() {
+	p.mu.Lock()
+	p.token = authToken{}
+	p.mu.Unlock()
+	p.grant.refreshToken = ""
+	if p.store != nil {
+		if err := p.store.Remove(); err != nil {
+			log.Printf("Error while removing stored auth token: %v\n", err)
+		}
+	}
+}
+
+// authorize waits until user grants access to the app and returns authorization code
+func (p *codeFlowTokenPoller) authorize(ctx context.Context) (code string, err error) {
+	state, err := p.newState()
+	if err != nil {
+		return "", err
+	}
+	redirect, err := url.Parse(p.grant.redirectURI)
+	if err != nil {
+		err = fmt.Errorf("invalid redirect uri: %w", err)
+		return "", err
+	}
+	ln, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		err = fmt.Errorf("couldn't start authorization callback listener: %w", err)
+		return "", err
+	}
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+	result := make(chan callbackResult, 1)
+	mux := http.NewServeMux()
+	mux.Handle(path, callbackHandler(state, result))
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	log.Printf("Open the following URL to authorize the application: %v\n", p.authorizeRequestURL(state))
+	select {
+	case res := <-result:
+		if res.err != nil {
+			return "", res.err
+		}
+		log.Println("Application authorized")
+		return res.code, nil
+	case <-ctx.Done():
+		err = fmt.Errorf("waiting for authorization were interrupted")
+		return "", err
+	}
+}
+
+func (p *codeFlowTokenPoller) authorizeRequestURL(state string) string {
+	params := url.Values{}
+	params.Set("client_id", p.creds.ClientId)
+	params.Set("response_type", "code")
+	params.Set("state", state)
+	params.Set("redirect_uri", p.grant.redirectURI)
+	params.Set("duration", "permanent")
+	params.Set("scope", strings.Join(p.scopes, " "))
+	return p.authorizeURL + "?" + params.Encode()
+}
+
+// callbackHandler handles redirect from authorization page. Requests with unexpected state are rejected
+// without reporting the result, so forged requests can't abort authorization.
+func callbackHandler(state string, result chan<- callbackResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "Invalid state", http.StatusBadRequest)
+			return
+		}
+		var res callbackResult
+		if e := query.Get("error"); e != "" {
+			res.err = fmt.Errorf("authorization failed: %v", e)
+			w.Write([]byte("Authorization failed: " + e))
+		} else if query.Get("code") == "" {
+			// the page could be reloaded without parameters, so authorization isn't aborted
+			http.Error(w, "Missing code", http.StatusBadRequest)
+			return
+		} else {
+			res.code = query.Get("code")
+			w.Write([]byte("Application authorized, you can close this page"))
+		}
+		select {
+		case result <- res:
+		default: // result has been already reported
+		}
+	})
+}
+
+func randomState() (state string, err error) {
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = fmt.Errorf("couldn't generate authorization state: %w", err)
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
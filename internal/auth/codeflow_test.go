@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizeRequestURL(t *testing.T) {
+	tp := NewCodeFlowTokenPoller("blank", 100, 0, Credentials{ClientId: "clientId"},
+		CodeFlowParams{
+			AuthorizeURL: "https://www.reddit.com/api/v1/authorize",
+			RedirectURI:  "http://localhost:8080/callback",
+			Scopes:       []string{"identity", "read", "save"},
+		}).(*codeFlowTokenPoller)
+
+	actual, err := url.Parse(tp.authorizeRequestURL("someState"))
+	if err != nil {
+		t.Fatalf("Failed to parse authorize URL, err: %v", err)
+	}
+	assert.Equal(t, "www.reddit.com", actual.Host)
+	assert.Equal(t, "/api/v1/authorize", actual.Path)
+	expectedParams := url.Values{
+		"client_id":     {"clientId"},
+		"response_type": {"code"},
+		"state":         {"someState"},
+		"redirect_uri":  {"http://localhost:8080/callback"},
+		"duration":      {"permanent"},
+		"scope":         {"identity read save"},
+	}
+	assert.Equal(t, expectedParams, actual.Query())
+}
+
+func TestCallbackHandler(t *testing.T) {
+	cases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       *callbackResult
+	}{
+		{
+			"success",
+			"state=someState&code=someCode",
+			http.StatusOK,
+			&callbackResult{code: "someCode"},
+		},
+		{
+			"accessDenied",
+			"state=someState&error=access_denied",
+			http.StatusOK,
+			&callbackResult{err: fmt.Errorf("authorization failed: access_denied")},
+		},
+		{
+			"missingCode",
+			"state=someState",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"invalidState",
+			"state=otherState&code=someCode",
+			http.StatusBadRequest,
+			nil,
+		},
+	}
+	for _, c := range cases {
+		result := make(chan callbackResult, 1)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/callback?"+c.query, nil)
+		callbackHandler("someState", result).ServeHTTP(w, r)
+
+		assert.Equal(t, c.expectedStatus, w.Code, c.name)
+		if c.expected == nil {
+			assert.Empty(t, result, c.name)
+			continue
+		}
+		assert.Equal(t, *c.expected, <-result, c.name)
+	}
+}
+
+func TestCodeGrantRequestBody(t *testing.T) {
+	grant := &codeGrant{code: "someCode", redirectURI: "http://localhost:8080/callback"}
+	expected := "code=someCode&grant_type=authorization_code&redirect_uri=http%3A%2F%2Flocalhost%3A8080%2Fcallback"
+	actual, _ := grant.requestBody(Credentials{})
+	assert.Equal(t, expected, actual)
+
+	grant.tokenIssued(&authResponse{AccessToken: "someValue", RefreshToken: "refreshValue"})
+	actual, _ = grant.requestBody(Credentials{})
+	assert.Equal(t, "grant_type=refresh_token&refresh_token=refreshValue", actual)
+
+	// refresh token isn't returned on refresh
+	grant.tokenIssued(&authResponse{AccessToken: "otherValue"})
+	actual, _ = grant.requestBody(Credentials{})
+	assert.Equal(t, "grant_type=refresh_token&refresh_token=refreshValue", actual)
+}
+
+func TestCodeFlowPollerStart(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != "someCode" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"someValue","refresh_token":"refreshValue","expires_in":3600}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	redirectURI := freeRedirectURI(t)
+	tp := NewCodeFlowTokenPoller(ts.URL, 180, 0, Credentials{ClientId: "clientId"},
+		CodeFlowParams{AuthorizeURL: "blank", RedirectURI: redirectURI}).(*codeFlowTokenPoller)
+	tp.newState = func() (string, error) {
+		return "someState", nil
+	}
+	go emulateRedirect(redirectURI + "?state=someState&code=someCode")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	exit, err := tp.Start(ctx)
+	assert.Nil(t, err)
+	assert.NotNil(t, exit)
+	assert.Equal(t, "someValue", tp.TokenValue())
+	assert.Equal(t, "refreshValue", tp.grant.refreshToken)
+}
+
+func TestCodeFlowPollerStartWithRevokedToken(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.WriteHeader(http.StatusOK)
+		if r.PostForm.Get("grant_type") == "refresh_token" {
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"someValue","refresh_token":"newRefreshValue","expires_in":3600}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	store := NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"), "")
+	store.Save(&StoredToken{ClientId: "clientId", RefreshToken: "revokedValue"})
+	redirectURI := freeRedirectURI(t)
+	tp := NewCodeFlowTokenPoller(ts.URL, 180, 0, Credentials{ClientId: "clientId"},
+		CodeFlowParams{AuthorizeURL: "blank", RedirectURI: redirectURI}, WithTokenStore(store)).(*codeFlowTokenPoller)
+	tp.newState = func() (string, error) {
+		return "someState", nil
+	}
+	go emulateRedirect(redirectURI + "?state=someState&code=someCode")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := tp.Start(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "someValue", tp.TokenValue())
+	stored, _ := store.Load()
+	assert.Equal(t, "newRefreshValue", stored.RefreshToken)
+}
+
+// freeRedirectURI returns callback URI on a free local port
+func freeRedirectURI(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port, err: %v", err)
+	}
+	defer ln.Close()
+	return "http://" + ln.Addr().String() + "/callback"
+}
+
+// emulateRedirect emulates browser redirect once callback listener is up
+func emulateRedirect(url string) {
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
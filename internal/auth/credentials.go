@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+type (
+	// CredentialsProvider supplies credentials for auth token requests.
+	// Token poller asks for credentials on every token request, so rotated secrets are picked up.
+	CredentialsProvider interface {
+		Credentials() (creds Credentials, err error)
+	}
+
+	// envCredentials reads PREFIX_CLIENT_ID, PREFIX_CLIENT_SECRET, PREFIX_USERNAME, PREFIX_PASSWORD
+	// and PREFIX_TOTP_SECRET env variables. If variable isn't set, the file at path from the variable
+	// with _FILE suffix is read instead, as Docker and Kubernetes secrets are usually mounted.
+	envCredentials struct {
+		prefix string
+	}
+
+	// secretsFileCredentials reads the same keys as envCredentials from KEY=VALUE lines of a file
+	secretsFileCredentials struct {
+		path string
+	}
+
+	// commandCredentials runs external helper which prints credentials JSON to stdout
+	commandCredentials struct {
+		command []string
+	}
+
+	credentialsJSON struct {
+		ClientId     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		UserName     string `json:"username"`
+		Password     string `json:"password"`
+		TOTPSecret   string `json:"totp_secret"`
+	}
+)
+
+const credentialsCommandTimeout = 30 * time.Second
+
+func NewEnvCredentials(prefix string) (p CredentialsProvider) {
+	p = &envCredentials{prefix: prefix}
+	return p
+}
+
+func NewSecretsFileCredentials(path string) (p CredentialsProvider) {
+	p = &secretsFileCredentials{path: path}
+	return p
+}
+
+func NewCommandCredentials(command []string) (p CredentialsProvider) {
+	p = &commandCredentials{command: command}
+	return p
+}
+
+func (c *envCredentials) Credentials() (creds Credentials, err error) {
+	values := make(map[string]string)
+	for _, key := range []string{"CLIENT_ID", "CLIENT_SECRET", "USERNAME", "PASSWORD", "TOTP_SECRET"} {
+		name := c.prefix + key
+		if v, ok := os.LookupEnv(name); ok {
+			values[key] = v
+			continue
+		}
+		path, ok := os.LookupEnv(name + "_FILE")
+		if !ok {
+			continue
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			err = fmt.Errorf("couldn't read %v_FILE: %w", name, err)
+			return creds, err
+		}
+		values[key] = strings.TrimSpace(string(b))
+	}
+	return credentialsFromMap(values), nil
+}
+
+func (c *secretsFileCredentials) Credentials() (creds Credentials, err error) {
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		err = fmt.Errorf("couldn't read secrets file: %w", err)
+		return creds, err
+	}
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			// line content isn't reported since it could hold a secret
+			err = fmt.Errorf("invalid secrets file line %d: no '=' separator", n)
+			return creds, err
+		}
+		values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return credentialsFromMap(values), nil
+}
+
+func (c *commandCredentials) Credentials() (creds Credentials, err error) {
+	if len(c.command) == 0 {
+		err = fmt.Errorf("credentials helper command isn't set")
+		return creds, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), credentialsCommandTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.command[0], c.command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		err = fmt.Errorf("credentials helper failed: %w: %v", err, strings.TrimSpace(stderr.String()))
+		return creds, err
+	}
+	var decoded credentialsJSON
+	err = json.Unmarshal(stdout.Bytes(), &decoded)
+	if err != nil {
+		err = fmt.Errorf("error decoding credentials helper output: %w", err)
+		return creds, err
+	}
+	creds = Credentials{
+		UserName:     decoded.UserName,
+		Password:     decoded.Password,
+		ClientId:     decoded.ClientId,
+		ClientSecret: decoded.ClientSecret,
+		TOTPSecret:   decoded.TOTPSecret,
+	}
+	return creds, nil
+}
+
+func credentialsFromMap(values map[string]string) (creds Credentials) {
+	creds = Credentials{
+		UserName:     values["USERNAME"],
+		Password:     values["PASSWORD"],
+		ClientId:     values["CLIENT_ID"],
+		ClientSecret: values["CLIENT_SECRET"],
+		TOTPSecret:   values["TOTP_SECRET"],
+	}
+	return creds
+}
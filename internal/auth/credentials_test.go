@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	os.WriteFile(passwordFile, []byte("Doe\n"), 0600)
+
+	t.Setenv("TEST_CLIENT_ID", "clientId")
+	t.Setenv("TEST_CLIENT_SECRET", "clientSecret")
+	t.Setenv("TEST_USERNAME", "Jhon")
+	t.Setenv("TEST_PASSWORD_FILE", passwordFile)
+
+	expected := Credentials{UserName: "Jhon", Password: "Doe", ClientId: "clientId", ClientSecret: "clientSecret"}
+	actual, err := NewEnvCredentials("TEST_").Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+
+	t.Setenv("TEST_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	_, err = NewEnvCredentials("TEST_").Credentials()
+	assert.NotNil(t, err)
+}
+
+func TestSecretsFileCredentials(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		expected *Credentials
+	}{
+		{
+			"success",
+			"# bot account\nCLIENT_ID=clientId\nCLIENT_SECRET = clientSecret\nUSERNAME=Jhon\nPASSWORD=a=b\n",
+			&Credentials{UserName: "Jhon", Password: "a=b", ClientId: "clientId", ClientSecret: "clientSecret"},
+		},
+		{
+			"invalidLine",
+			"CLIENT_ID\n",
+			nil,
+		},
+	}
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "secrets")
+		os.WriteFile(path, []byte(c.content), 0600)
+
+		actual, err := NewSecretsFileCredentials(path).Credentials()
+		if c.expected == nil {
+			assert.NotNil(t, err, c.name)
+			continue
+		}
+		assert.Nil(t, err, c.name)
+		assert.Equal(t, *c.expected, actual, c.name)
+	}
+}
+
+func TestSecretsFileCredentialsErrorHidesLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+	os.WriteFile(path, []byte("CLIENT_ID=clientId\nsuperSecretPassword\n"), 0600)
+
+	_, err := NewSecretsFileCredentials(path).Credentials()
+
+	assert.EqualError(t, err, "invalid secrets file line 2: no '=' separator")
+}
+
+func TestCommandCredentials(t *testing.T) {
+	cases := []struct {
+		name     string
+		command  []string
+		expected *Credentials
+	}{
+		{
+			"success",
+			[]string{"echo", `{"client_id":"clientId","client_secret":"clientSecret","username":"Jhon","password":"Doe"}`},
+			&Credentials{UserName: "Jhon", Password: "Doe", ClientId: "clientId", ClientSecret: "clientSecret"},
+		},
+		{
+			"invalidOutput",
+			[]string{"echo", "not json"},
+			nil,
+		},
+		{
+			"commandFailure",
+			[]string{"false"},
+			nil,
+		},
+	}
+	for _, c := range cases {
+		actual, err := NewCommandCredentials(c.command).Credentials()
+		if c.expected == nil {
+			assert.NotNil(t, err, c.name)
+			continue
+		}
+		assert.Nil(t, err, c.name)
+		assert.Equal(t, *c.expected, actual, c.name)
+	}
+}
@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type (
+	// AuthError describes failed auth token request
+	AuthError struct {
+		StatusCode int    // zero if no response has been received
+		Code       string // OAuth error code reported by Reddit, e.g. "invalid_grant"
+		Err        error
+	}
+
+	// StopError is sent to the poller exit channel when token can't be refreshed anymore
+	StopError struct {
+		Reason  error // one of ErrCredentialsRejected, ErrOutageExceeded
+		LastErr error
+	}
+)
+
+var (
+	ErrCredentialsRejected = errors.New("credentials rejected by auth server")
+	ErrOutageExceeded      = errors.New("auth token refresh outage exceeded")
+)
+
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// Terminal reports whether retrying the same request is pointless
+func (e *AuthError) Terminal() bool {
+	if e.Code != "" {
+		return true
+	}
+	return e.StatusCode >= http.StatusBadRequest && e.StatusCode < http.StatusInternalServerError &&
+		e.StatusCode != http.StatusTooManyRequests
+}
+
+func (e *StopError) Error() string {
+	return fmt.Sprintf("auth token poller stopped: %v: %v", e.Reason, e.LastErr)
+}
+
+func (e *StopError) Unwrap() []error {
+	return []error{e.Reason, e.LastErr}
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"net/url"
+	"time"
+)
+
+type (
+	// tokenGrant produces access token requests of particular OAuth2 flow.
+	// Token refreshes are serialized by the poller, so grants don't need own locking.
+	tokenGrant interface {
+		// requestBody returns form encoded body of access token request
+		requestBody(creds Credentials) (body string, err error)
+		// tokenIssued is called with every successful access token response
+		tokenIssued(resp *authResponse)
+		// userContext reports whether issued tokens act on behalf of a user account
+		userContext() bool
+	}
+
+	// passwordGrant is used by script apps acting on behalf of the developer account.
+	// Current TOTP code is appended to the password if account has two-factor authentication.
+	passwordGrant struct{}
+
+	// codeGrant exchanges authorization code for tokens once and then uses the refresh token
+	codeGrant struct {
+		code         string
+		redirectURI  string
+		refreshToken string
+	}
+
+	// clientCredentialsGrant is used by confidential clients for app-only access
+	clientCredentialsGrant struct{}
+
+	// installedClientGrant is used by installed apps without client secret for app-only access
+	installedClientGrant struct {
+		deviceId string
+	}
+)
+
+const installedClientGrantType = "https://oauth.reddit.com/grants/installed_client"
+
+func (g passwordGrant) requestBody(creds Credentials) (body string, err error) {
+	password := creds.Password
+	if creds.TOTPSecret != "" {
+		code, err := totpCode(creds.TOTPSecret, time.Now())
+		if err != nil {
+			return "", err
+		}
+		password += ":" + code
+	}
+	params := url.Values{}
+	params.Set("grant_type", "password")
+	params.Set("username", creds.UserName)
+	params.Set("password", password)
+	return params.Encode(), nil
+}
+
+func (g passwordGrant) tokenIssued(resp *authResponse) {}
+
+func (g passwordGrant) userContext() bool {
+	return true
+}
+
+func (g *codeGrant) requestBody(creds Credentials) (body string, err error) {
+	params := url.Values{}
+	if g.refreshToken != "" {
+		params.Set("grant_type", "refresh_token")
+		params.Set("refresh_token", g.refreshToken)
+		return params.Encode(), nil
+	}
+	params.Set("grant_type", "authorization_code")
+	params.Set("code", g.code)
+	params.Set("redirect_uri", g.redirectURI)
+	return params.Encode(), nil
+}
+
+func (g *codeGrant) tokenIssued(resp *authResponse) {
+	// authorization code is single use, refresh token is returned for permanent authorization only
+	g.code = ""
+	if resp.RefreshToken != "" {
+		g.refreshToken = resp.RefreshToken
+	}
+}
+
+func (g *codeGrant) userContext() bool {
+	return true
+}
+
+func (g clientCredentialsGrant) requestBody(creds Credentials) (body string, err error) {
+	params := url.Values{}
+	params.Set("grant_type", "client_credentials")
+	return params.Encode(), nil
+}
+
+func (g clientCredentialsGrant) tokenIssued(resp *authResponse) {}
+
+func (g clientCredentialsGrant) userContext() bool {
+	return false
+}
+
+func (g installedClientGrant) requestBody(creds Credentials) (body string, err error) {
+	params := url.Values{}
+	params.Set("grant_type", installedClientGrantType)
+	params.Set("device_id", g.deviceId)
+	return params.Encode(), nil
+}
+
+func (g installedClientGrant) tokenIssued(resp *authResponse) {}
+
+func (g installedClientGrant) userContext() bool {
+	return false
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// TokenStore persists auth tokens between application runs
+	TokenStore interface {
+		Load() (t *StoredToken, err error)
+		Save(t *StoredToken) error
+		Remove() error
+	}
+
+	StoredToken struct {
+		ClientId     string    `json:"client_id"`
+		UserName     string    `json:"username"`
+		AccessToken  string    `json:"access_token"`
+		RefreshToken string    `json:"refresh_token"`
+		Scope        string    `json:"scope"`
+		Lifetime     int       `json:"lifetime"`
+		IssuedAt     time.Time `json:"issued_at"`
+		ExpiresAt    time.Time `json:"expires_at"`
+	}
+
+	fileTokenStore struct {
+		path       string
+		passphrase string
+	}
+
+	encryptedToken struct {
+		Salt       []byte `json:"salt"`
+		Nonce      []byte `json:"nonce"`
+		Ciphertext []byte `json:"ciphertext"`
+	}
+)
+
+const (
+	keyDerivationIterations = 100000
+	keyLength               = 32 // AES-256
+	saltLength              = 16
+)
+
+// NewFileTokenStore creates token store keeping the token in file readable by owner only.
+// The file is encrypted with AES-GCM if passphrase isn't empty.
+func NewFileTokenStore(path string, passphrase string) (s TokenStore) {
+	s = &fileTokenStore{path: path, passphrase: passphrase}
+	return s
+}
+
+// Load returns nil token if there is no stored token yet
+func (s *fileTokenStore) Load() (t *StoredToken, err error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		err = fmt.Errorf("couldn't read token file: %w", err)
+		return nil, err
+	}
+	if s.passphrase != "" {
+		data, err = s.decrypt(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	t = &StoredToken{}
+	err = json.Unmarshal(data, t)
+	if err != nil {
+		err = fmt.Errorf("couldn't unmarshall token file: %w", err)
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *fileTokenStore) Save(t *StoredToken) (err error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if s.passphrase != "" {
+		data, err = s.encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+	// write to temporary file first, so the stored token is never partially written
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		err = fmt.Errorf("couldn't create token file: %w", err)
+		return err
+	}
+	defer os.Remove(f.Name())
+	err = f.Chmod(0600)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = fmt.Errorf("couldn't write token file: %w", err)
+		return err
+	}
+	err = os.Rename(f.Name(), s.path)
+	if err != nil {
+		err = fmt.Errorf("couldn't write token file: %w", err)
+		return err
+	}
+	return nil
+}
+
+func (s *fileTokenStore) Remove() (err error) {
+	err = os.Remove(s.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		err = fmt.Errorf("couldn't remove token file: %w", err)
+		return err
+	}
+	return nil
+}
+
+func (s *fileTokenStore) encrypt(data []byte) (encrypted []byte, err error) {
+	salt := make([]byte, saltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(s.passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedToken{Salt: salt, Nonce: nonce, Ciphertext: gcm.Seal(nil, nonce, data, nil)})
+}
+
+func (s *fileTokenStore) decrypt(data []byte) (decrypted []byte, err error) {
+	var e encryptedToken
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		err = fmt.Errorf("couldn't unmarshall encrypted token file: %w", err)
+		return nil, err
+	}
+	gcm, err := newGCM(s.passphrase, e.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted token nonce")
+	}
+	decrypted, err = gcm.Open(nil, e.Nonce, e.Ciphertext, nil)
+	if err != nil {
+		err = fmt.Errorf("couldn't decrypt token file, wrong passphrase?: %w", err)
+		return nil, err
+	}
+	return decrypted, nil
+}
+
+func newGCM(passphrase string, salt []byte) (gcm cipher.AEAD, err error) {
+	block, err := aes.NewCipher(deriveKey([]byte(passphrase), salt))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// deriveKey implements PBKDF2 with HMAC-SHA256 for single block of output, which is enough for AES-256 key
+func deriveKey(passphrase, salt []byte) []byte {
+	prf := hmac.New(sha256.New, passphrase)
+	prf.Write(salt)
+	prf.Write([]byte{0, 0, 0, 1})
+	u := prf.Sum(nil)
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < keyDerivationIterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key[:keyLength]
+}
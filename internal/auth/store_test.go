@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	cases := []struct {
+		name       string
+		passphrase string
+	}{
+		{
+			"plain",
+			"",
+		},
+		{
+			"encrypted",
+			"somePassphrase",
+		},
+	}
+	for _, c := range cases {
+		path := filepath.Join(t.TempDir(), "token.json")
+		store := NewFileTokenStore(path, c.passphrase)
+
+		actual, err := store.Load()
+		assert.Nil(t, err, c.name)
+		assert.Nil(t, actual, c.name)
+
+		expected := &StoredToken{
+			ClientId:     "clientId",
+			UserName:     "Jhon",
+			AccessToken:  "someValue",
+			RefreshToken: "refreshValue",
+			Lifetime:     3600,
+			ExpiresAt:    time.Now().Add(time.Hour).Round(0),
+		}
+		err = store.Save(expected)
+		assert.Nil(t, err, c.name)
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat token file, err: %v", err)
+		}
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), c.name)
+
+		content, _ := os.ReadFile(path)
+		if c.passphrase != "" {
+			assert.NotContains(t, string(content), "someValue", c.name)
+		}
+
+		actual, err = store.Load()
+		assert.Nil(t, err, c.name)
+		assert.True(t, expected.ExpiresAt.Equal(actual.ExpiresAt), c.name)
+		actual.ExpiresAt = expected.ExpiresAt
+		assert.Equal(t, expected, actual, c.name)
+
+		assert.Nil(t, store.Remove(), c.name)
+		actual, err = store.Load()
+		assert.Nil(t, err, c.name)
+		assert.Nil(t, actual, c.name)
+	}
+}
+
+func TestFileTokenStoreWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	err := NewFileTokenStore(path, "somePassphrase").Save(&StoredToken{AccessToken: "someValue"})
+	assert.Nil(t, err)
+
+	actual, err := NewFileTokenStore(path, "otherPassphrase").Load()
+	assert.NotNil(t, err)
+	assert.Nil(t, actual)
+}
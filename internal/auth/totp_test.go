@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTotpCode(t *testing.T) {
+	// RFC 6238 test vectors for SHA1, truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	cases := []struct {
+		unixTime int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, c := range cases {
+		actual, err := totpCode(secret, time.Unix(c.unixTime, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, c.expected, actual)
+	}
+
+	// secrets are often displayed in lower case groups
+	actual, err := totpCode("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, "287082", actual)
+}
@@ -3,7 +3,9 @@ package appconfig
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 
 	yamlV3 "gopkg.in/yaml.v3"
@@ -11,17 +13,39 @@ import (
 
 type (
 	AppConfig struct {
-		Auth   AuthConfig   `yaml:"auth"`
-		Client ClientConfig `yaml:"client"`
+		Auth     AuthConfig      `yaml:"auth"`
+		Accounts []AccountConfig `yaml:"accounts"`
+		Client   ClientConfig    `yaml:"client"`
+	}
+
+	// AccountConfig describes Reddit account the app acts as. Auth settings which aren't set
+	// are inherited from the common auth config, except token store and credentials which are per account.
+	AccountConfig struct {
+		Name      string     `yaml:"name"`
+		EnvPrefix string     `yaml:"envPrefix"` // prefix of credentials env variables
+		Auth      AuthConfig `yaml:"auth"`
 	}
 
 	AuthConfig struct {
-		Host          string `yaml:"host"`
-		RequestPeriod uint   `yaml:"requestPeriod"`
-		ClientId      string
-		ClientSecret  string
-		Username      string
-		Password      string
+		Host            string            `yaml:"host"`
+		RequestPeriod   uint              `yaml:"requestPeriod"`
+		MaxOutage       uint              `yaml:"maxOutage"`
+		Flow            string            `yaml:"flow"`
+		AuthorizeUrl    string            `yaml:"authorizeUrl"`
+		RedirectUri     string            `yaml:"redirectUri"`
+		Scopes          string            `yaml:"scopes"`
+		DeviceId        string            `yaml:"deviceId"`
+		TokenStore      string            `yaml:"tokenStore"`
+		RevokeUrl       string            `yaml:"revokeUrl"`
+		Credentials     CredentialsConfig `yaml:"credentials"`
+		TokenPassphrase string
+	}
+
+	// CredentialsConfig describes where Reddit credentials are read from
+	CredentialsConfig struct {
+		Source  string   `yaml:"source"`
+		Path    string   `yaml:"path"`    // secrets file path, for "file" source
+		Command []string `yaml:"command"` // helper command with arguments, for "command" source
 	}
 
 	ClientConfig struct {
@@ -36,9 +60,31 @@ type (
 	Subreddit struct {
 		Name     string `yaml:"name"`
 		Keywords string `yaml:"keywords"`
+		Account  string `yaml:"account"` // could be omitted if there is only one account
 	}
 )
 
+// OAuth2 flows supported for auth token requests
+const (
+	FlowPassword          = "password"
+	FlowCode              = "code"
+	FlowClientCredentials = "client_credentials" // app-only
+	FlowInstalledClient   = "installed_client"   // app-only
+)
+
+const (
+	// DefaultAccount is the name of the account created from common auth config if no accounts are declared
+	DefaultAccount   = "default"
+	defaultEnvPrefix = "REDDITAPI_"
+)
+
+// Credentials sources
+const (
+	CredentialsEnv     = "env" // env variables or files referenced by *_FILE env variables
+	CredentialsFile    = "file"
+	CredentialsCommand = "command"
+)
+
 var cfg *AppConfig
 var once sync.Once
 
@@ -62,10 +108,164 @@ func load(path string) {
 		err = fmt.Errorf("couldn't unmarshall config file: %w", err)
 		log.Fatalln(err)
 	}
-	cfg.Auth.ClientId = os.Getenv("REDDITAPI_CLIENT_ID")
-	cfg.Auth.ClientSecret = os.Getenv("REDDITAPI_CLIENT_SECRET")
-	cfg.Auth.Username = os.Getenv("REDDITAPI_USERNAME")
-	cfg.Auth.Password = os.Getenv("REDDITAPI_PASSWORD")
+	if cfg.Auth.Flow == "" {
+		cfg.Auth.Flow = FlowPassword
+	}
+	resolveCredentials(&cfg.Auth, defaultEnvPrefix)
+	resolveAccounts()
 
 	log.Println("Config loaded")
 }
+
+// validateAuth stops the app if token refresh period isn't set, or the auth flow is unknown or its settings are missing
+func validateAuth(account string, auth AuthConfig) {
+	if err := checkAuth(auth); err != nil {
+		log.Fatalf("invalid auth config of account %q: %v\n", account, err)
+	}
+}
+
+func checkAuth(auth AuthConfig) (err error) {
+	if auth.RequestPeriod == 0 {
+		err = fmt.Errorf("requestPeriod must be positive")
+		return err
+	}
+	return checkFlow(auth)
+}
+
+func checkFlow(auth AuthConfig) (err error) {
+	switch auth.Flow {
+	case FlowPassword, FlowClientCredentials:
+	case FlowCode:
+		if auth.AuthorizeUrl == "" || auth.RedirectUri == "" {
+			err = fmt.Errorf("authorizeUrl and redirectUri are required for code flow")
+			return err
+		}
+		if len(auth.ScopeList()) == 0 {
+			err = fmt.Errorf("scopes are required for code flow")
+			return err
+		}
+		// callback listener is started on redirectUri host, so the port has to be explicit
+		redirect, err := url.Parse(auth.RedirectUri)
+		if err != nil || redirect.Scheme != "http" || redirect.Hostname() == "" || redirect.Port() == "" {
+			err = fmt.Errorf("redirectUri must be http URL with host and port, e.g. http://localhost:8080/callback")
+			return err
+		}
+	case FlowInstalledClient:
+		if auth.DeviceId == "" {
+			err = fmt.Errorf("deviceId is required for installed client flow")
+			return err
+		}
+	default:
+		err = fmt.Errorf("unknown auth flow %q", auth.Flow)
+		return err
+	}
+	return nil
+}
+
+// ScopeList returns scopes listed in comma separated Scopes setting
+func (c AuthConfig) ScopeList() (scopes []string) {
+	for _, scope := range strings.Split(c.Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// resolveCredentials validates credentials source, the credentials themselves are read by the token poller
+func resolveCredentials(auth *AuthConfig, envPrefix string) {
+	switch auth.Credentials.Source {
+	case "":
+		auth.Credentials.Source = CredentialsEnv
+	case CredentialsEnv:
+	case CredentialsFile:
+		if auth.Credentials.Path == "" {
+			log.Fatalln("path is required for file credentials source")
+		}
+	case CredentialsCommand:
+		if len(auth.Credentials.Command) == 0 {
+			log.Fatalln("command is required for command credentials source")
+		}
+	default:
+		log.Fatalf("unknown credentials source %q\n", auth.Credentials.Source)
+	}
+	auth.TokenPassphrase = os.Getenv(envPrefix + "TOKEN_PASSPHRASE")
+}
+
+// resolveAccounts completes accounts config and binds subreddits to accounts
+func resolveAccounts() {
+	if len(cfg.Accounts) == 0 {
+		cfg.Accounts = []AccountConfig{{Name: DefaultAccount, EnvPrefix: defaultEnvPrefix, Auth: cfg.Auth}}
+		validateAuth(DefaultAccount, cfg.Auth)
+	} else {
+		names := make(map[string]bool)
+		for i := range cfg.Accounts {
+			acc := &cfg.Accounts[i]
+			if acc.Name == "" {
+				log.Fatalln("account name is required")
+			}
+			if names[acc.Name] {
+				log.Fatalf("duplicate account %q\n", acc.Name)
+			}
+			names[acc.Name] = true
+			if acc.EnvPrefix == "" {
+				acc.EnvPrefix = defaultEnvPrefix + strings.ToUpper(acc.Name) + "_"
+			}
+			inheritAuth(&acc.Auth, cfg.Auth)
+			validateAuth(acc.Name, acc.Auth)
+			resolveCredentials(&acc.Auth, acc.EnvPrefix)
+		}
+	}
+
+	for i := range cfg.Client.Subreddits {
+		sub := &cfg.Client.Subreddits[i]
+		if sub.Account == "" {
+			if len(cfg.Accounts) > 1 {
+				log.Fatalf("account isn't set for subreddit %q\n", sub.Name)
+			}
+			sub.Account = cfg.Accounts[0].Name
+		}
+		if cfg.FindAccount(sub.Account) == nil {
+			log.Fatalf("unknown account %q for subreddit %q\n", sub.Account, sub.Name)
+		}
+	}
+}
+
+func inheritAuth(auth *AuthConfig, common AuthConfig) {
+	if auth.Host == "" {
+		auth.Host = common.Host
+	}
+	if auth.RequestPeriod == 0 {
+		auth.RequestPeriod = common.RequestPeriod
+	}
+	if auth.MaxOutage == 0 {
+		auth.MaxOutage = common.MaxOutage
+	}
+	if auth.Flow == "" {
+		auth.Flow = common.Flow
+	}
+	if auth.AuthorizeUrl == "" {
+		auth.AuthorizeUrl = common.AuthorizeUrl
+	}
+	if auth.RedirectUri == "" {
+		auth.RedirectUri = common.RedirectUri
+	}
+	if auth.Scopes == "" {
+		auth.Scopes = common.Scopes
+	}
+	if auth.DeviceId == "" {
+		auth.DeviceId = common.DeviceId
+	}
+	if auth.RevokeUrl == "" {
+		auth.RevokeUrl = common.RevokeUrl
+	}
+}
+
+func (c *AppConfig) FindAccount(name string) *AccountConfig {
+	for i := range c.Accounts {
+		if c.Accounts[i].Name == name {
+			return &c.Accounts[i]
+		}
+	}
+	return nil
+}
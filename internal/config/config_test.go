@@ -8,14 +8,21 @@ import (
 )
 
 func TestConfigLoad(t *testing.T) {
+	expectedAuth := AuthConfig{
+		Host:          "https://www.reddit.com/api/v1/access_token",
+		RequestPeriod: 180,
+		MaxOutage:     600,
+		Flow:          FlowPassword,
+		Credentials:   CredentialsConfig{Source: CredentialsEnv},
+	}
 	expectedConfig := &AppConfig{
-		AuthConfig{
-			Host:          "https://www.reddit.com/api/v1/access_token",
-			RequestPeriod: 180,
-			ClientId:      "testClientId",
-			ClientSecret:  "testClientSecret",
-			Username:      "Jhon",
-			Password:      "Doe",
+		expectedAuth,
+		[]AccountConfig{
+			{
+				Name:      DefaultAccount,
+				EnvPrefix: "REDDITAPI_",
+				Auth:      expectedAuth,
+			},
 		},
 		ClientConfig{
 			Host:          "https://oauth.reddit.com",
@@ -27,22 +34,150 @@ func TestConfigLoad(t *testing.T) {
 				{
 					"golang",
 					"slice, map, update, news",
+					DefaultAccount,
 				},
 				{
 					"wallstreetbets",
 					"stock, share, bond",
+					DefaultAccount,
 				},
 			},
 		},
 	}
 
-	os.Setenv("REDDITAPI_CLIENT_ID", "testClientId")
-	os.Setenv("REDDITAPI_CLIENT_SECRET", "testClientSecret")
-	os.Setenv("REDDITAPI_USERNAME", "Jhon")
-	os.Setenv("REDDITAPI_PASSWORD", "Doe")
 	LoadConfig("../../testdata/appconfig/test_config.yml")
 	actualConfig := cfg
 
 	assert.Equal(t, expectedConfig, actualConfig)
 
 }
+
+func TestConfigLoadAccounts(t *testing.T) {
+	expectedAccounts := []AccountConfig{
+		{
+			Name:      "main",
+			EnvPrefix: "REDDITAPI_MAIN_",
+			Auth: AuthConfig{
+				Host:            "https://www.reddit.com/api/v1/access_token",
+				RequestPeriod:   180,
+				MaxOutage:       600,
+				Flow:            FlowPassword,
+				AuthorizeUrl:    "https://www.reddit.com/api/v1/authorize",
+				Credentials:     CredentialsConfig{Source: CredentialsEnv},
+				TokenPassphrase: "mainPassphrase",
+			},
+		},
+		{
+			Name:      "helper",
+			EnvPrefix: "HELPER_",
+			Auth: AuthConfig{
+				Host:          "https://www.reddit.com/api/v1/access_token",
+				RequestPeriod: 180,
+				MaxOutage:     600,
+				Flow:          FlowCode,
+				AuthorizeUrl:  "https://www.reddit.com/api/v1/authorize",
+				RedirectUri:   "http://localhost:8080/callback",
+				Scopes:        "identity, read, save",
+				TokenStore:    "helper_token.json",
+				Credentials: CredentialsConfig{
+					Source:  CredentialsCommand,
+					Command: []string{"vault-helper", "--account", "helper"},
+				},
+			},
+		},
+	}
+
+	// config loaded once by LoadConfig is restored for other tests
+	defer func(loaded *AppConfig) { cfg = loaded }(cfg)
+	os.Setenv("REDDITAPI_MAIN_TOKEN_PASSPHRASE", "mainPassphrase")
+	load("../../testdata/appconfig/test_accounts_config.yml")
+	actualConfig := cfg
+
+	assert.Equal(t, expectedAccounts, actualConfig.Accounts)
+	assert.Equal(t, "main", actualConfig.Client.Subreddits[0].Account)
+	assert.Equal(t, "helper", actualConfig.Client.Subreddits[1].Account)
+	assert.Equal(t, &actualConfig.Accounts[1], actualConfig.FindAccount("helper"))
+	assert.Nil(t, actualConfig.FindAccount("unknown"))
+}
+
+func TestCheckFlow(t *testing.T) {
+	cases := []struct {
+		name        string
+		auth        AuthConfig
+		expectedErr bool
+	}{
+		{
+			"password",
+			AuthConfig{Flow: FlowPassword},
+			false,
+		},
+		{
+			"code",
+			AuthConfig{Flow: FlowCode, AuthorizeUrl: "https://www.reddit.com/api/v1/authorize",
+				RedirectUri: "http://localhost:8080/callback", Scopes: "read, save"},
+			false,
+		},
+		{
+			"codeWithoutRedirectUri",
+			AuthConfig{Flow: FlowCode, AuthorizeUrl: "https://www.reddit.com/api/v1/authorize", Scopes: "read"},
+			true,
+		},
+		{
+			"codeWithoutAuthorizeUrl",
+			AuthConfig{Flow: FlowCode, RedirectUri: "http://localhost:8080/callback", Scopes: "read"},
+			true,
+		},
+		{
+			"codeWithoutRedirectPort",
+			AuthConfig{Flow: FlowCode, AuthorizeUrl: "https://www.reddit.com/api/v1/authorize",
+				RedirectUri: "http://localhost/callback", Scopes: "read"},
+			true,
+		},
+		{
+			"codeWithHttpsRedirect",
+			AuthConfig{Flow: FlowCode, AuthorizeUrl: "https://www.reddit.com/api/v1/authorize",
+				RedirectUri: "https://localhost:8443/callback", Scopes: "read"},
+			true,
+		},
+		{
+			"codeWithEmptyScopes",
+			AuthConfig{Flow: FlowCode, AuthorizeUrl: "https://www.reddit.com/api/v1/authorize",
+				RedirectUri: "http://localhost:8080/callback", Scopes: " , "},
+			true,
+		},
+		{
+			"installedClient",
+			AuthConfig{Flow: FlowInstalledClient, DeviceId: "DO_NOT_TRACK_THIS_DEVICE"},
+			false,
+		},
+		{
+			"installedClientWithoutDeviceId",
+			AuthConfig{Flow: FlowInstalledClient},
+			true,
+		},
+		{
+			"unknown",
+			AuthConfig{Flow: "implicit"},
+			true,
+		},
+	}
+	for _, c := range cases {
+		err := checkFlow(c.auth)
+		if c.expectedErr {
+			assert.NotNil(t, err, c.name)
+		} else {
+			assert.Nil(t, err, c.name)
+		}
+	}
+}
+
+func TestCheckAuth(t *testing.T) {
+	assert.Nil(t, checkAuth(AuthConfig{RequestPeriod: 180, Flow: FlowPassword}))
+	assert.NotNil(t, checkAuth(AuthConfig{Flow: FlowPassword}))
+	assert.NotNil(t, checkAuth(AuthConfig{RequestPeriod: 180, Flow: "implicit"}))
+}
+
+func TestScopeList(t *testing.T) {
+	assert.Equal(t, []string{"identity", "read", "save"}, AuthConfig{Scopes: "identity, read,,save "}.ScopeList())
+	assert.Nil(t, AuthConfig{}.ScopeList())
+}
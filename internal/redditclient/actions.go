@@ -0,0 +1,85 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	unsaveUrl     = "/api/unsave"
+	hideUrl       = "/api/hide"
+	unhideUrl     = "/api/unhide"
+	voteUrl       = "/api/vote"
+	markNSFWUrl   = "/api/marknsfw"
+	unmarkNSFWUrl = "/api/unmarknsfw"
+	spoilerUrl    = "/api/spoiler"
+	unspoilerUrl  = "/api/unspoiler"
+	reportUrl     = "/api/report"
+)
+
+func (cl *rateLimitedClient) UnsavePost(ctx context.Context, name string) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.postAction(ctx, "UnsavePost", cl.host+unsaveUrl, params)
+}
+
+// Hide hides posts from listings of the user
+func (cl *rateLimitedClient) Hide(ctx context.Context, names ...string) (err error) {
+	params := make(map[string]string)
+	params["id"] = strings.Join(names, ",")
+	return cl.postAction(ctx, "Hide", cl.host+hideUrl, params)
+}
+
+func (cl *rateLimitedClient) Unhide(ctx context.Context, names ...string) (err error) {
+	params := make(map[string]string)
+	params["id"] = strings.Join(names, ",")
+	return cl.postAction(ctx, "Unhide", cl.host+unhideUrl, params)
+}
+
+// Vote votes for the post or comment, VoteNone clears the vote
+func (cl *rateLimitedClient) Vote(ctx context.Context, name string, dir int) (err error) {
+	switch dir {
+	case api.VoteUp, api.VoteNone, api.VoteDown:
+	default:
+		err = fmt.Errorf("invalid vote direction %v", dir)
+		return err
+	}
+	params := make(map[string]string)
+	params["id"] = name
+	params["dir"] = strconv.Itoa(dir)
+	return cl.postAction(ctx, "Vote", cl.host+voteUrl, params)
+}
+
+// MarkNSFW marks the post as NSFW or removes the mark
+func (cl *rateLimitedClient) MarkNSFW(ctx context.Context, name string, nsfw bool) (err error) {
+	url := cl.host + markNSFWUrl
+	if !nsfw {
+		url = cl.host + unmarkNSFWUrl
+	}
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.postAction(ctx, "MarkNSFW", url, params)
+}
+
+// Spoiler marks the post as spoiler or removes the mark
+func (cl *rateLimitedClient) Spoiler(ctx context.Context, name string, spoiler bool) (err error) {
+	url := cl.host + spoilerUrl
+	if !spoiler {
+		url = cl.host + unspoilerUrl
+	}
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.postAction(ctx, "Spoiler", url, params)
+}
+
+// Report reports the post, comment or message to subreddit moderators
+func (cl *rateLimitedClient) Report(ctx context.Context, name string, reason string) (err error) {
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["thing_id"] = name
+	params["reason"] = reason
+	return cl.postAction(ctx, "Report", cl.host+reportUrl, params)
+}
@@ -0,0 +1,146 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActions(t *testing.T) {
+	cases := []struct {
+		name          string
+		action        func(cl api.RedditAPIClient) error
+		expectedQuery string
+		expectedErr   bool
+	}{
+		{
+			"saveToCategory",
+			func(cl api.RedditAPIClient) error {
+				return cl.SavePost(context.Background(), "t3_abc", "golang")
+			},
+			"/api/save?category=golang&id=t3_abc",
+			false,
+		},
+		{
+			"unsave",
+			func(cl api.RedditAPIClient) error {
+				return cl.UnsavePost(context.Background(), "t3_abc")
+			},
+			"/api/unsave?id=t3_abc",
+			false,
+		},
+		{
+			"hide",
+			func(cl api.RedditAPIClient) error {
+				return cl.Hide(context.Background(), "t3_abc", "t3_def")
+			},
+			"/api/hide?id=t3_abc%2Ct3_def",
+			false,
+		},
+		{
+			"unhide",
+			func(cl api.RedditAPIClient) error {
+				return cl.Unhide(context.Background(), "t3_abc")
+			},
+			"/api/unhide?id=t3_abc",
+			false,
+		},
+		{
+			"downvote",
+			func(cl api.RedditAPIClient) error {
+				return cl.Vote(context.Background(), "t1_abc", api.VoteDown)
+			},
+			"/api/vote?dir=-1&id=t1_abc",
+			false,
+		},
+		{
+			"invalidVoteDirection",
+			func(cl api.RedditAPIClient) error {
+				return cl.Vote(context.Background(), "t1_abc", 2)
+			},
+			"",
+			true,
+		},
+		{
+			"markNSFW",
+			func(cl api.RedditAPIClient) error {
+				return cl.MarkNSFW(context.Background(), "t3_abc", true)
+			},
+			"/api/marknsfw?id=t3_abc",
+			false,
+		},
+		{
+			"unmarkNSFW",
+			func(cl api.RedditAPIClient) error {
+				return cl.MarkNSFW(context.Background(), "t3_abc", false)
+			},
+			"/api/unmarknsfw?id=t3_abc",
+			false,
+		},
+		{
+			"spoiler",
+			func(cl api.RedditAPIClient) error {
+				return cl.Spoiler(context.Background(), "t3_abc", true)
+			},
+			"/api/spoiler?id=t3_abc",
+			false,
+		},
+		{
+			"unspoiler",
+			func(cl api.RedditAPIClient) error {
+				return cl.Spoiler(context.Background(), "t3_abc", false)
+			},
+			"/api/unspoiler?id=t3_abc",
+			false,
+		},
+		{
+			"report",
+			func(cl api.RedditAPIClient) error {
+				return cl.Report(context.Background(), "t3_abc", "spam")
+			},
+			"/api/report?api_type=json&reason=spam&thing_id=t3_abc",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		var query, method string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			query = r.URL.Path + "?" + r.PostForm.Encode()
+			method = r.Method
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write([]byte("{}"))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+		actualErr := c.action(cl)
+
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		if c.expectedErr {
+			assert.NotNil(t, actualErr, c.name)
+		} else {
+			assert.Nil(t, actualErr, c.name)
+			assert.Equal(t, http.MethodPost, method, c.name)
+		}
+		ts.Close()
+	}
+}
+
+func TestActionsScopes(t *testing.T) {
+	tp := &TokenPollerMock{scopes: []string{api.ScopeRead, api.ScopeSave}}
+	cl := NewClient("http://localhost", "/new", "/api/save", "dummy", tp)
+
+	err := cl.Vote(context.Background(), "t3_abc", api.VoteUp)
+
+	var scopeErr *ScopeError
+	assert.ErrorAs(t, err, &scopeErr)
+	assert.Equal(t, api.ScopeVote, scopeErr.Scope)
+}
@@ -0,0 +1,285 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	commentsUrl     = "/comments/"
+	moreChildrenUrl = "/api/morechildren"
+	commentUrl      = "/api/comment"
+	editUserTextUrl = "/api/editusertext"
+	deleteUrl       = "/api/del"
+	// maxMoreChildren is the max number of comment ids morechildren endpoint accepts at once
+	maxMoreChildren = 100
+)
+
+// thingsResponse is a response of API methods returning things, e.g. morechildren or comment
+type thingsResponse struct {
+	Json struct {
+		Data struct {
+			Things []api.Thing `json:"things"`
+		} `json:"data"`
+	} `json:"json"`
+}
+
+// GetComments requests the post and its comment tree. Article is post id with or without t3_ prefix.
+func (cl *rateLimitedClient) GetComments(ctx context.Context, article string, opts api.CommentsOptions) (thread *api.Thread, err error) {
+	err = cl.checkMethod("GetComments")
+	if err != nil {
+		return nil, err
+	}
+	url := cl.host + commentsUrl + strings.TrimPrefix(article, api.KindLink+"_")
+	params := make(map[string]string)
+	if opts.Sort != "" {
+		params["sort"] = opts.Sort
+	}
+	if opts.Depth > 0 {
+		params["depth"] = strconv.Itoa(opts.Depth)
+	}
+	if opts.Limit > 0 {
+		params["limit"] = strconv.Itoa(opts.Limit)
+	}
+	if opts.Comment != "" {
+		params["comment"] = strings.TrimPrefix(opts.Comment, api.KindComment+"_")
+	}
+
+	resp, err := cl.sendApiRequest(ctx, http.MethodGet, url, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// response is a pair of listings: the post and top level comments
+	var listings []api.Listing
+	err = json.NewDecoder(resp.Body).Decode(&listings)
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling comments response: article=%v: %w", article, err)
+		return nil, err
+	}
+	if len(listings) != 2 || len(listings[0].Links()) != 1 {
+		err = fmt.Errorf("unexpected comments response: article=%v", article)
+		return nil, err
+	}
+	thread = &api.Thread{
+		Post:     listings[0].Links()[0],
+		Comments: &listings[1],
+		Sort:     opts.Sort,
+	}
+	return thread, nil
+}
+
+// ExpandMore requests comments hidden behind "more" stubs in batches and nests them into the tree.
+// "Continue this thread" stubs, which have no comment ids, are left as is.
+// On error the stubs which weren't expanded yet are put back, so expanding could be retried.
+func (cl *rateLimitedClient) ExpandMore(ctx context.Context, thread *api.Thread) (err error) {
+	err = cl.checkMethod("ExpandMore")
+	if err != nil {
+		return err
+	}
+	tree := &commentTree{
+		post:     thread.Post.Name,
+		comments: thread.Comments,
+		replies:  make(map[string]*api.Comment),
+	}
+	// the same comment ids aren't requested twice, so expanding stops even if server keeps returning stubs
+	requested := make(map[string]bool)
+	stubs := tree.collect(thread.Comments)
+	for len(stubs) > 0 {
+		stub := stubs[0]
+		stubs = stubs[1:]
+		var ids []string
+		for _, id := range stub.Children {
+			if !requested[id] {
+				requested[id] = true
+				ids = append(ids, id)
+			}
+		}
+		for start := 0; start < len(ids); start += maxMoreChildren {
+			end := start + maxMoreChildren
+			if end > len(ids) {
+				end = len(ids)
+			}
+			things, err := cl.moreChildren(ctx, thread, ids[start:end])
+			if err != nil {
+				stub.Children = ids[start:]
+				tree.restore(append([]*api.More{stub}, stubs...))
+				return err
+			}
+			for _, thing := range things {
+				stubs = append(stubs, tree.attach(thing, stub.ParentId)...)
+			}
+		}
+	}
+	return nil
+}
+
+func (cl *rateLimitedClient) moreChildren(ctx context.Context, thread *api.Thread, children []string) (things []api.Thing, err error) {
+	url := cl.host + moreChildrenUrl
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["link_id"] = thread.Post.Name
+	params["children"] = strings.Join(children, ",")
+	if thread.Sort != "" {
+		params["sort"] = thread.Sort
+	}
+
+	resp, err := cl.sendApiRequest(ctx, http.MethodGet, url, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var more thingsResponse
+	err = json.NewDecoder(resp.Body).Decode(&more)
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling morechildren response: link=%v: %w", thread.Post.Name, err)
+		return nil, err
+	}
+	return more.Json.Data.Things, nil
+}
+
+// Comment replies to the post or comment with markdown text and returns the created comment.
+// Messages are rejected before the request is sent, as their replies are messages, see ReplyToMessage.
+func (cl *rateLimitedClient) Comment(ctx context.Context, parent string, markdown string) (comment *api.Comment, err error) {
+	if strings.HasPrefix(parent, api.KindMessage+"_") {
+		err = fmt.Errorf("message %v can't be replied with Comment, use ReplyToMessage", parent)
+		return nil, err
+	}
+	thing, err := cl.postUserText(ctx, "Comment", cl.host+commentUrl, parent, markdown)
+	if err != nil {
+		return nil, err
+	}
+	comment, ok := thing.Data.(*api.Comment)
+	if !ok {
+		err = fmt.Errorf("unexpected thing kind %q in comment response: parent=%v", thing.Kind, parent)
+		return nil, err
+	}
+	return comment, nil
+}
+
+// EditUserText replaces markdown text of the comment or self post, edited thing holds *Comment or *Link
+func (cl *rateLimitedClient) EditUserText(ctx context.Context, name string, markdown string) (thing *api.Thing, err error) {
+	return cl.postUserText(ctx, "EditUserText", cl.host+editUserTextUrl, name, markdown)
+}
+
+// Delete deletes the post or comment
+func (cl *rateLimitedClient) Delete(ctx context.Context, name string) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.postAction(ctx, "Delete", cl.host+deleteUrl, params)
+}
+
+// postUserText sends markdown text of the thing and returns the thing created or updated
+func (cl *rateLimitedClient) postUserText(ctx context.Context, method string, url string, name string, markdown string) (thing *api.Thing, err error) {
+	err = cl.checkMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["thing_id"] = name
+	params["text"] = markdown
+
+	resp, err := cl.sendApiRequest(ctx, http.MethodPost, url, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var things thingsResponse
+	err = json.NewDecoder(resp.Body).Decode(&things)
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling %v response: thing=%v: %w", method, name, err)
+		return nil, err
+	}
+	if len(things.Json.Data.Things) != 1 {
+		err = fmt.Errorf("unexpected number of things in %v response: thing=%v: %v", method, name, len(things.Json.Data.Things))
+		return nil, err
+	}
+	return &things.Json.Data.Things[0], nil
+}
+
+// commentTree indexes comments of a thread by fullname to nest expanded comments
+type commentTree struct {
+	post     string
+	comments *api.Listing
+	replies  map[string]*api.Comment
+}
+
+// collect indexes comments of the listing and its replies and removes expandable "more" stubs from it
+func (t *commentTree) collect(l *api.Listing) (stubs []*api.More) {
+	if l == nil {
+		return nil
+	}
+	children := l.Children[:0]
+	for _, child := range l.Children {
+		switch v := child.Data.(type) {
+		case *api.More:
+			if len(v.Children) > 0 {
+				stubs = append(stubs, v)
+				continue
+			}
+		case *api.Comment:
+			t.replies[v.Name] = v
+			stubs = append(stubs, t.collect(v.Replies)...)
+		}
+		children = append(children, child)
+	}
+	l.Children = children
+	return stubs
+}
+
+// attach nests expanded thing under its parent, or under the stub parent if the parent isn't in the tree.
+// It returns "more" stubs which have to be expanded further.
+func (t *commentTree) attach(thing api.Thing, stubParent string) (stubs []*api.More) {
+	var parent string
+	switch v := thing.Data.(type) {
+	case *api.Comment:
+		parent = v.ParentId
+	case *api.More:
+		if len(v.Children) > 0 {
+			return []*api.More{v}
+		}
+		parent = v.ParentId
+	default:
+		return nil
+	}
+	if parent != t.post && t.replies[parent] == nil {
+		parent = stubParent
+	}
+	listing := t.listing(parent)
+	listing.Children = append(listing.Children, thing)
+
+	if c, ok := thing.Data.(*api.Comment); ok {
+		t.replies[c.Name] = c
+		return t.collect(c.Replies)
+	}
+	return nil
+}
+
+// restore puts "more" stubs back under their parents
+func (t *commentTree) restore(stubs []*api.More) {
+	for _, stub := range stubs {
+		listing := t.listing(stub.ParentId)
+		listing.Children = append(listing.Children, api.Thing{Kind: api.KindMore, Data: stub})
+	}
+}
+
+// listing returns replies of the parent comment, or top level comments if the parent isn't in the tree
+func (t *commentTree) listing(parent string) *api.Listing {
+	c := t.replies[parent]
+	if c == nil {
+		return t.comments
+	}
+	if c.Replies == nil {
+		c.Replies = &api.Listing{}
+	}
+	return c.Replies
+}
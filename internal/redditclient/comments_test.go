@@ -0,0 +1,253 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func commentThing(id, parent string) map[string]interface{} {
+	return map[string]interface{}{
+		"kind": api.KindComment,
+		"data": map[string]interface{}{"id": id, "name": "t1_" + id, "parent_id": parent, "link_id": "t3_abc"},
+	}
+}
+
+func TestGetComments(t *testing.T) {
+	responseBytes := readFile("../../testdata/redditclient/commentsResponse.json", t)
+	var query string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Path + "?" + r.URL.RawQuery
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		w.Write(responseBytes)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", &TokenPollerMock{})
+	thread, err := cl.GetComments(context.Background(), "t3_abc",
+		api.CommentsOptions{Sort: api.CommentSortTop, Depth: 3, Limit: 50})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/comments/abc?depth=3&limit=50&sort=top", query)
+	assert.Equal(t, "Thread title", thread.Post.Title)
+	assert.Equal(t, api.CommentSortTop, thread.Sort)
+	comments := thread.Comments.Comments()
+	assert.Equal(t, 1, len(comments))
+	assert.Equal(t, "first", comments[0].Body)
+	assert.Equal(t, "reply", comments[0].Replies.Comments()[0].Body)
+	assert.Equal(t, 3, len(thread.Comments.Children))
+}
+
+func TestExpandMore(t *testing.T) {
+	responseBytes := readFile("../../testdata/redditclient/commentsResponse.json", t)
+	var batches [][]string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		if r.URL.Path != moreChildrenUrl {
+			w.Write(responseBytes)
+			return
+		}
+		assert.Equal(t, "t3_abc", r.URL.Query().Get("link_id"))
+		assert.Equal(t, api.CommentSortNew, r.URL.Query().Get("sort"))
+		ids := strings.Split(r.URL.Query().Get("children"), ",")
+		batches = append(batches, ids)
+		var things []interface{}
+		for _, id := range ids {
+			switch id {
+			case "c":
+				things = append(things, commentThing("c", "t1_a"), map[string]interface{}{
+					"kind": api.KindMore,
+					"data": map[string]interface{}{"id": "g", "name": "t1_g", "parent_id": "t1_c", "count": 1, "children": []string{"g"}},
+				})
+			case "d":
+				things = append(things, commentThing("d", "t1_a"), commentThing("f", "t1_d"))
+			case "g":
+				things = append(things, commentThing("g", "t1_c"))
+			default:
+				things = append(things, commentThing(id, "t3_abc"))
+			}
+		}
+		resp := map[string]interface{}{"json": map[string]interface{}{
+			"errors": []interface{}{},
+			"data":   map[string]interface{}{"things": things},
+		}}
+		json.NewEncoder(w).Encode(resp)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", &TokenPollerMock{})
+	thread, err := cl.GetComments(context.Background(), "abc", api.CommentsOptions{Sort: api.CommentSortNew})
+	assert.Nil(t, err)
+
+	err = cl.ExpandMore(context.Background(), thread)
+
+	assert.Nil(t, err)
+	batchSizes := make([]int, 0)
+	for _, b := range batches {
+		batchSizes = append(batchSizes, len(b))
+	}
+	assert.Equal(t, []int{2, 100, 20, 1}, batchSizes)
+
+	topLevel := thread.Comments.Comments()
+	assert.Equal(t, 121, len(topLevel))
+	// "continue this thread" stub is kept
+	assert.Equal(t, 122, len(thread.Comments.Children))
+
+	replyNames := func(c *api.Comment) (names []string) {
+		for _, r := range c.Replies.Comments() {
+			names = append(names, r.Name)
+		}
+		return names
+	}
+	first := topLevel[0]
+	assert.Equal(t, []string{"t1_b", "t1_c", "t1_d"}, replyNames(first))
+	assert.Equal(t, 3, len(first.Replies.Children))
+	assert.Equal(t, []string{"t1_g"}, replyNames(first.Replies.Comments()[1]))
+	assert.Equal(t, []string{"t1_f"}, replyNames(first.Replies.Comments()[2]))
+}
+
+func TestExpandMoreError(t *testing.T) {
+	responseBytes := readFile("../../testdata/redditclient/commentsResponse.json", t)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		if r.URL.Path != moreChildrenUrl {
+			w.Write(responseBytes)
+			return
+		}
+		w.Write([]byte(`{"json": {"errors": [["RATELIMIT", "you are doing that too much", "ratelimit"]]}}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", &TokenPollerMock{})
+	thread, err := cl.GetComments(context.Background(), "abc", api.CommentsOptions{})
+	assert.Nil(t, err)
+
+	err = cl.ExpandMore(context.Background(), thread)
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	// stubs aren't lost, so expanding could be retried
+	assert.Equal(t, 3, len(thread.Comments.Children))
+	first := thread.Comments.Comments()[0]
+	assert.Equal(t, 2, len(first.Replies.Children))
+	var stubIds [][]string
+	for _, l := range []*api.Listing{thread.Comments, first.Replies} {
+		for _, child := range l.Children {
+			if more, ok := child.Data.(*api.More); ok && len(more.Children) > 0 {
+				stubIds = append(stubIds, more.Children)
+			}
+		}
+	}
+	assert.Equal(t, 2, len(stubIds))
+	assert.Equal(t, 120, len(stubIds[0]))
+	assert.Equal(t, []string{"c", "d"}, stubIds[1])
+}
+
+func TestPostUserText(t *testing.T) {
+	commentBody := `{"json": {"errors": [], "data": {"things": [{"kind": "t1", "data": {"id": "xyz", "name": "t1_xyz", "parent_id": "t3_abc", "body": "**reply**", "created_utc": 1689700100.0}}]}}}`
+	linkBody := `{"json": {"errors": [], "data": {"things": [{"kind": "t3", "data": {"id": "abc", "name": "t3_abc", "selftext": "edited"}}]}}}`
+	messageBody := `{"json": {"errors": [], "data": {"things": [{"kind": "t4", "data": {"id": "xyz", "name": "t4_xyz", "parent_id": "t4_abc", "body": "**reply**", "created_utc": 1689700100.0}}]}}}`
+	cases := []struct {
+		name          string
+		call          func(cl api.RedditAPIClient) (interface{}, error)
+		responseBody  string
+		expectedQuery string
+		expectedData  interface{}
+	}{
+		{
+			"comment",
+			func(cl api.RedditAPIClient) (interface{}, error) {
+				return cl.Comment(context.Background(), "t3_abc", "**reply**")
+			},
+			commentBody,
+			"/api/comment?api_type=json&text=%2A%2Areply%2A%2A&thing_id=t3_abc",
+			"**reply**",
+		},
+		{
+			"replyToMessage",
+			func(cl api.RedditAPIClient) (interface{}, error) {
+				return cl.ReplyToMessage(context.Background(), "t4_abc", "**reply**")
+			},
+			messageBody,
+			"/api/comment?api_type=json&text=%2A%2Areply%2A%2A&thing_id=t4_abc",
+			"**reply**",
+		},
+		{
+			"editSelfPost",
+			func(cl api.RedditAPIClient) (interface{}, error) {
+				return cl.EditUserText(context.Background(), "t3_abc", "edited")
+			},
+			linkBody,
+			"/api/editusertext?api_type=json&text=edited&thing_id=t3_abc",
+			"edited",
+		},
+		{
+			"delete",
+			func(cl api.RedditAPIClient) (interface{}, error) {
+				return nil, cl.Delete(context.Background(), "t1_xyz")
+			},
+			`{}`,
+			"/api/del?id=t1_xyz",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		var query string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			query = r.URL.Path + "?" + r.PostForm.Encode()
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write([]byte(c.responseBody))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", &TokenPollerMock{})
+		result, err := c.call(cl)
+
+		assert.Nil(t, err, c.name)
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		switch v := result.(type) {
+		case *api.Comment:
+			assert.Equal(t, c.expectedData, v.Body, c.name)
+			assert.Equal(t, "t1_xyz", v.Name, c.name)
+		case *api.Message:
+			assert.Equal(t, c.expectedData, v.Body, c.name)
+			assert.Equal(t, "t4_xyz", v.Name, c.name)
+		case *api.Thing:
+			assert.Equal(t, c.expectedData, v.Data.(*api.Link).Selftext, c.name)
+		}
+		ts.Close()
+	}
+}
+
+func TestCommentRejectsMessage(t *testing.T) {
+	requested := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer ts.Close()
+
+	cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", &TokenPollerMock{})
+	comment, err := cl.Comment(context.Background(), "t4_abc", "**reply**")
+
+	assert.Nil(t, comment)
+	assert.NotNil(t, err)
+	assert.False(t, requested)
+}
@@ -0,0 +1,198 @@
+package redditclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors matched by APIError with errors.Is
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("reddit server error")
+
+	ErrSubredditBanned  = errors.New("subreddit is banned")
+	ErrSubredditPrivate = errors.New("subreddit is private")
+
+	ErrSubredditNotAllowed = errors.New("not allowed to submit to subreddit")
+	ErrAlreadySubmitted    = errors.New("link has already been submitted")
+)
+
+// Reasons of inaccessible subreddit responses
+const (
+	bannedReason  = "banned"
+	privateReason = "private"
+)
+
+// Reddit error codes
+const (
+	rateLimitCode           = "RATELIMIT" // user does some action too often
+	subredditNotAllowedCode = "SUBREDDIT_NOTALLOWED"
+	alreadySubmittedCode    = "ALREADY_SUB"
+)
+
+// codeErrors are sentinel errors matched by Reddit error codes
+var codeErrors = map[error]string{
+	ErrSubredditNotAllowed: subredditNotAllowedCode,
+	ErrAlreadySubmitted:    alreadySubmittedCode,
+}
+
+type (
+	// UserContextError is returned when API method acting on behalf of a user is called with app-only auth token
+	UserContextError struct {
+		Method string
+	}
+
+	// ScopeError is returned when auth token isn't granted the scope required by API method
+	ScopeError struct {
+		Method string
+		Scope  string
+	}
+
+	// SubredditError is returned when subreddit can't be monitored, e.g. it doesn't exist or is private
+	SubredditError struct {
+		Subreddit string
+		Reason    string
+		Err       error // API error, if subreddit is rejected by Reddit
+	}
+
+	// ModPermissionError is returned when moderation action is rejected, as the account isn't
+	// a moderator of the subreddit or lacks moderator permissions
+	ModPermissionError struct {
+		Method string
+		Name   string // fullname of moderated thing
+		Err    error
+	}
+
+	// APIError is returned when Reddit rejects API request, either with non-200 status code
+	// or with errors in JSON response body
+	APIError struct {
+		StatusCode int
+		Method     string // HTTP method
+		Url        string
+		Params     map[string]string
+		Message    string        // error message of non-200 response, if any
+		Reason     string        // e.g. "private" or "banned" for inaccessible subreddits
+		Errors     []RedditError // errors reported in "json.errors" of response body
+	}
+
+	// RedditError is a single error from "json.errors" of Reddit response,
+	// e.g. ["RATELIMIT", "you are doing that too much", "ratelimit"]
+	RedditError struct {
+		Code    string
+		Message string
+		Field   string // name of request field the error relates to, if any
+	}
+
+	// errorBody is the part of Reddit response body describing errors
+	errorBody struct {
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+		Json    struct {
+			Errors []RedditError `json:"errors"`
+		} `json:"json"`
+	}
+)
+
+func (e *UserContextError) Error() string {
+	return fmt.Sprintf("API method %v requires user context, but app-only auth token is used", e.Method)
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("API method %v requires %q scope, which isn't granted to auth token", e.Method, e.Scope)
+}
+
+func (e *SubredditError) Error() string {
+	return fmt.Sprintf("subreddit %q isn't available: %v", e.Subreddit, e.Reason)
+}
+
+func (e *SubredditError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ModPermissionError) Error() string {
+	return fmt.Sprintf("API method %v for %v requires moderator permissions: %v", e.Method, e.Name, e.Err)
+}
+
+func (e *ModPermissionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API request failed: method=%v, url=%v, params=%v: statusCode=%v", e.Method, e.Url, e.Params, e.StatusCode)
+	if e.Message != "" {
+		msg += ", message=" + e.Message
+	}
+	if e.Reason != "" {
+		msg += ", reason=" + e.Reason
+	}
+	if len(e.Errors) > 0 {
+		errs := make([]string, 0, len(e.Errors))
+		for _, v := range e.Errors {
+			errs = append(errs, v.String())
+		}
+		msg += ", errors=[" + strings.Join(errs, "; ") + "]"
+	}
+	return msg
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.HasCode(rateLimitCode)
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	case ErrSubredditBanned:
+		return e.Reason == bannedReason && (e.StatusCode == http.StatusForbidden || e.StatusCode == http.StatusNotFound)
+	case ErrSubredditPrivate:
+		return e.Reason == privateReason && e.StatusCode == http.StatusForbidden
+	}
+	if code, ok := codeErrors[target]; ok {
+		return e.HasCode(code)
+	}
+	return false
+}
+
+// HasCode reports whether Reddit returned error with the code
+func (e *APIError) HasCode(code string) bool {
+	for _, v := range e.Errors {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (e RedditError) String() string {
+	if e.Field == "" {
+		return e.Code + ": " + e.Message
+	}
+	return e.Code + ": " + e.Message + " (" + e.Field + ")"
+}
+
+// UnmarshalJSON decodes error from [code, message, field] array, where field could be null
+func (e *RedditError) UnmarshalJSON(b []byte) (err error) {
+	var items []interface{}
+	err = json.Unmarshal(b, &items)
+	if err != nil {
+		return err
+	}
+	fields := []*string{&e.Code, &e.Message, &e.Field}
+	for i := 0; i < len(items) && i < len(fields); i++ {
+		if v, ok := items[i].(string); ok {
+			*fields[i] = v
+		}
+	}
+	return nil
+}
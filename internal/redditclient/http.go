@@ -1,13 +1,17 @@
 package redditclient
 
 import (
+	"bytes"
 	"context"
 	"dmmak/redditapi/internal/api"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -61,11 +65,22 @@ func (cl *rateLimitedClient) updateRateLimit(resp *http.Response) (err error) {
 	return nil
 }
 
-func (cl *rateLimitedClient) setRequestParams(req *http.Request, paramMap map[string]string) {
-	authToken := cl.authTokenPoller.TokenValue()
+func (cl *rateLimitedClient) setRequestParams(req *http.Request, authToken string, paramMap map[string]string) {
 	bearer := "Bearer " + authToken
 	req.Header.Add("Authorization", bearer)
 	req.Header.Add("User-Agent", cl.userAgent)
+	if req.Method == http.MethodPost {
+		// POST params are sent as form, as some of them are too long for URL, e.g. self post text
+		form := make(url.Values)
+		for k, v := range paramMap {
+			form.Add(k, v)
+		}
+		encoded := form.Encode()
+		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req.Body = io.NopCloser(strings.NewReader(encoded))
+		req.ContentLength = int64(len(encoded))
+		return
+	}
 	params := req.URL.Query()
 	for k, v := range paramMap {
 		params.Add(k, v)
@@ -74,13 +89,87 @@ func (cl *rateLimitedClient) setRequestParams(req *http.Request, paramMap map[st
 }
 
 func (cl *rateLimitedClient) sendApiRequest(ctx context.Context, method string, url string, params map[string]string) (resp *http.Response, err error) {
+	return cl.sendRequest(ctx, method, url, params, nil)
+}
+
+// sendJsonRequest sends API request with JSON encoded body, e.g. preferences patch
+func (cl *rateLimitedClient) sendJsonRequest(ctx context.Context, method string, url string, body interface{}) (resp *http.Response, err error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		err = fmt.Errorf("error while marshalling API request body: url=%v: %w", url, err)
+		return nil, err
+	}
+	return cl.sendRequest(ctx, method, url, nil, jsonBody)
+}
+
+func (cl *rateLimitedClient) sendRequest(ctx context.Context, method string, url string, params map[string]string, jsonBody []byte) (resp *http.Response, err error) {
+	resp, authToken, err := cl.doApiRequest(ctx, method, url, params, jsonBody)
+	if err != nil {
+		return nil, err
+	}
+	// auth token could be revoked or expired earlier than expected, so refresh it and retry once
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		log.Printf("API request unauthorized, refreshing auth token: url=%v\n", url)
+		err = cl.authTokenPoller.ForceRefresh(ctx, authToken)
+		if err != nil {
+			// poller errors, e.g. StopError of rejected credentials, are passed as is
+			return nil, err
+		}
+		resp, _, err = cl.doApiRequest(ctx, method, url, params, jsonBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Reddit reports some errors in body of 200 responses, so the body is checked before it's returned
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		err = fmt.Errorf("error while reading API response: url=%v, params=%v: %w", url, params, err)
+		return nil, err
+	}
+	var errBody errorBody
+	// body isn't necessarily an object, e.g. comments response is an array, so decoding errors are ignored
+	_ = json.Unmarshal(body, &errBody)
+	if resp.StatusCode != http.StatusOK || len(errBody.Json.Errors) > 0 {
+		err = &APIError{
+			StatusCode: resp.StatusCode,
+			Method:     method,
+			Url:        url,
+			Params:     params,
+			Message:    errBody.Message,
+			Reason:     errBody.Reason,
+			Errors:     errBody.Json.Errors,
+		}
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
 
+// doApiRequest sends single API request and returns the response along with auth token used
+func (cl *rateLimitedClient) doApiRequest(ctx context.Context, method string, url string, params map[string]string, jsonBody []byte) (resp *http.Response, authToken string, err error) {
 	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		err = fmt.Errorf("error while creating API request: url=%v, params=%v: %w", url, params, err)
-		return nil, err
+		return nil, "", err
+	}
+	authToken = cl.authTokenPoller.TokenValue()
+	if authToken == "" {
+		// token expired, e.g. refresh is backing off or the host was suspended, so wait for the poller
+		// to refresh it instead of requesting the token endpoint beyond retry backoff
+		authToken, err = cl.authTokenPoller.WaitToken(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	cl.setRequestParams(req, authToken, params)
+	if jsonBody != nil {
+		req.Header.Add("Content-Type", "application/json")
+		req.Body = io.NopCloser(bytes.NewReader(jsonBody))
+		req.ContentLength = int64(len(jsonBody))
 	}
-	cl.setRequestParams(req, params)
 	// check rate limit params before sending request
 	if v := cl.rl.timeToWait(); v > 0 {
 		log.Printf("Wait for rate limit resetting for %v seconds\n", v)
@@ -89,60 +178,57 @@ func (cl *rateLimitedClient) sendApiRequest(ctx context.Context, method string,
 			log.Printf("Enable API requests")
 		case <-ctx.Done():
 			err = fmt.Errorf("waiting for rate limit resetting were interrupted")
-			return nil, err
+			return nil, "", err
 		}
 	}
 
 	resp, err = http.DefaultClient.Do(req)
 	if err != nil {
 		err = fmt.Errorf("error while requesting API method: url=%v, params=%v: %w", url, params, err)
-		return nil, err
+		return nil, "", err
 	}
 
 	err = cl.updateRateLimit(resp)
 	if err != nil {
 		log.Printf("Error updating rate limit value %v\n", err)
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("invalid response status code for API request: url=%v, params=%v: statusCode=%v", url, params, resp.StatusCode)
-		resp.Body.Close()
-		return nil, err
-	}
-	return resp, nil
+	return resp, authToken, nil
 }
 
-func (cl *rateLimitedClient) GetNewPosts(ctx context.Context, subreddit, lastPostName string) (newPosts *api.NewPostsResponse, err error) {
-	newPosts = &api.NewPostsResponse{}
-
+func (cl *rateLimitedClient) GetNewPosts(ctx context.Context, subreddit, lastPostName string) (newPosts *api.Listing, err error) {
 	url := cl.host + "/r/" + subreddit + cl.newPostsUrl
 	params := make(map[string]string)
 	params["limit"] = "10"
 	params["before"] = lastPostName
+	return cl.getListing(ctx, "GetNewPosts", url, params, false)
+}
 
-	resp, err := cl.sendApiRequest(ctx, http.MethodGet, url, params)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(newPosts)
-	if err != nil {
-		err = fmt.Errorf("error while umarshalling new subreddit posts response: %w", err)
-		return nil, err
-	}
-	return newPosts, nil
+func (cl *rateLimitedClient) NewPosts(subreddit string, opts api.ListingOptions) api.ListingIterator {
+	url := cl.host + "/r/" + subreddit + cl.newPostsUrl
+	return cl.newListingIterator("GetNewPosts", url, nil, opts)
 }
 
-func (cl *rateLimitedClient) SavePost(ctx context.Context, name string) (err error) {
+// SavePost saves the post or comment, optionally to the saved category
+func (cl *rateLimitedClient) SavePost(ctx context.Context, name string, category string) (err error) {
 	url := cl.host + cl.savePostUrl
 	params := make(map[string]string)
 	params["id"] = name
+	if category != "" {
+		params["category"] = category
+	}
+	return cl.postAction(ctx, "SavePost", url, params)
+}
 
+// postAction sends API request which changes state and has no meaningful response
+func (cl *rateLimitedClient) postAction(ctx context.Context, method string, url string, params map[string]string) (err error) {
+	err = cl.checkMethod(method)
+	if err != nil {
+		return err
+	}
 	resp, err := cl.sendApiRequest(ctx, http.MethodPost, url, params)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	resp.Body.Close()
 	return nil
 }
@@ -5,25 +5,69 @@ import (
 	"context"
 	"dmmak/redditapi/internal/api"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 type TokenPollerMock struct {
+	refreshes int32
+	waits     int32
+	appOnly   bool
+	scopes    []string
+	expired   bool  // no valid token until it's refreshed
+	waitErr   error // returned by WaitToken, e.g. StopError
 }
 
-func (p *TokenPollerMock) Start(ctx context.Context) (chan struct{}, error) {
+func (p *TokenPollerMock) Start(ctx context.Context) (<-chan error, error) {
 	return nil, nil
 }
 
 func (p *TokenPollerMock) TokenValue() string {
+	if atomic.LoadInt32(&p.refreshes) > 0 {
+		return "refreshedValue"
+	}
+	if p.expired {
+		return ""
+	}
 	return "someValue"
 }
 
+func (p *TokenPollerMock) AppOnly() bool {
+	return p.appOnly
+}
+
+func (p *TokenPollerMock) Status() api.TokenStatus {
+	return api.TokenStatus{Scopes: p.scopes}
+}
+
+func (p *TokenPollerMock) Revoke(ctx context.Context) error {
+	return nil
+}
+
+func (p *TokenPollerMock) ForceRefresh(ctx context.Context, staleToken string) error {
+	atomic.AddInt32(&p.refreshes, 1)
+	return nil
+}
+
+func (p *TokenPollerMock) WaitToken(ctx context.Context) (string, error) {
+	atomic.AddInt32(&p.waits, 1)
+	if p.waitErr != nil {
+		return "", p.waitErr
+	}
+	return "refreshedValue", nil
+}
+
+func (p *TokenPollerMock) ExpiresAt() time.Time {
+	return time.Time{}
+}
+
 func TestSendApiRequest(t *testing.T) {
 	cases := []struct {
 		name         string
@@ -72,6 +116,187 @@ func TestSendApiRequest(t *testing.T) {
 
 }
 
+func TestSendApiRequestUnauthorized(t *testing.T) {
+	cases := []struct {
+		name              string
+		validToken        string
+		expectedRefreshes int32
+	}{
+		{
+			"refreshedTokenAccepted",
+			"refreshedValue",
+			1,
+		},
+		{
+			"refreshedTokenRejected",
+			"otherValue",
+			1,
+		},
+	}
+
+	for _, c := range cases {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			if r.Header.Get("Authorization") != "Bearer "+c.validToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("{}"))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		tp := &TokenPollerMock{}
+		cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", tp).(*rateLimitedClient)
+
+		actualResponse, actualErr := cl.sendApiRequest(context.Background(), http.MethodGet, ts.URL, make(map[string]string))
+		if c.name == "refreshedTokenAccepted" {
+			assert.Nil(t, actualErr)
+			actualResponse.Body.Close()
+		}
+		if c.name == "refreshedTokenRejected" {
+			assert.NotNil(t, actualErr)
+		}
+		assert.Equal(t, c.expectedRefreshes, tp.refreshes)
+		ts.Close()
+	}
+}
+
+func TestSendApiRequestExpiredToken(t *testing.T) {
+	var authHeader string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		w.Write([]byte("{}"))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	tp := &TokenPollerMock{expired: true}
+	cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", tp).(*rateLimitedClient)
+
+	resp, err := cl.sendApiRequest(context.Background(), http.MethodGet, ts.URL, nil)
+
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer refreshedValue", authHeader)
+	// client waits for the poller instead of forcing refresh
+	assert.Equal(t, int32(1), tp.waits)
+	assert.Equal(t, int32(0), tp.refreshes)
+
+	// poller errors are passed as is
+	stopErr := errors.New("auth token poller stopped")
+	tp = &TokenPollerMock{expired: true, waitErr: stopErr}
+	cl = NewClient(ts.URL, "/dummy", "/dummy", "dummy", tp).(*rateLimitedClient)
+
+	_, err = cl.sendApiRequest(context.Background(), http.MethodGet, ts.URL, nil)
+
+	assert.Equal(t, stopErr, err)
+}
+
+func TestSendApiRequestErrors(t *testing.T) {
+	cases := []struct {
+		name          string
+		responseCode  int
+		responseBody  string
+		expectedErr   error
+		expectedError *APIError
+	}{
+		{
+			"unauthorized",
+			http.StatusUnauthorized,
+			`{"message": "Unauthorized", "error": 401}`,
+			ErrUnauthorized,
+			&APIError{StatusCode: http.StatusUnauthorized, Message: "Unauthorized"},
+		},
+		{
+			"forbidden",
+			http.StatusForbidden,
+			`{"reason": "private", "message": "Forbidden", "error": 403}`,
+			ErrForbidden,
+			&APIError{StatusCode: http.StatusForbidden, Message: "Forbidden", Reason: "private"},
+		},
+		{
+			"notFound",
+			http.StatusNotFound,
+			`{"message": "Not Found", "error": 404}`,
+			ErrNotFound,
+			&APIError{StatusCode: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			"tooManyRequests",
+			http.StatusTooManyRequests,
+			`<html>Too Many Requests</html>`,
+			ErrRateLimited,
+			&APIError{StatusCode: http.StatusTooManyRequests},
+		},
+		{
+			"serverError",
+			http.StatusBadGateway,
+			``,
+			ErrServer,
+			&APIError{StatusCode: http.StatusBadGateway},
+		},
+		{
+			"jsonErrors",
+			http.StatusOK,
+			`{"json": {"errors": [["RATELIMIT", "you are doing that too much", "ratelimit"], ["NO_TEXT", "we need something here", null]]}}`,
+			ErrRateLimited,
+			&APIError{
+				StatusCode: http.StatusOK,
+				Errors: []RedditError{
+					{Code: "RATELIMIT", Message: "you are doing that too much", Field: "ratelimit"},
+					{Code: "NO_TEXT", Message: "we need something here"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.WriteHeader(c.responseCode)
+			w.Write([]byte(c.responseBody))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", &TokenPollerMock{}).(*rateLimitedClient)
+		params := map[string]string{"id": "t3_151rq7s"}
+		_, actualErr := cl.sendApiRequest(context.Background(), http.MethodPost, ts.URL, params)
+
+		c.expectedError.Method = http.MethodPost
+		c.expectedError.Url = ts.URL
+		c.expectedError.Params = params
+		assert.Equal(t, c.expectedError, actualErr, c.name)
+		assert.ErrorIs(t, actualErr, c.expectedErr, c.name)
+		for _, sentinel := range []error{ErrUnauthorized, ErrForbidden, ErrNotFound, ErrRateLimited, ErrServer} {
+			if sentinel != c.expectedErr {
+				assert.NotErrorIs(t, actualErr, sentinel, c.name)
+			}
+		}
+		ts.Close()
+	}
+}
+
+func TestInaccessibleSubredditErrors(t *testing.T) {
+	banned := &APIError{StatusCode: http.StatusNotFound, Reason: "banned"}
+	private := &APIError{StatusCode: http.StatusForbidden, Reason: "private"}
+	forbidden := &APIError{StatusCode: http.StatusForbidden}
+
+	assert.ErrorIs(t, banned, ErrSubredditBanned)
+	assert.NotErrorIs(t, banned, ErrSubredditPrivate)
+	assert.ErrorIs(t, private, ErrSubredditPrivate)
+	assert.NotErrorIs(t, private, ErrSubredditBanned)
+	assert.NotErrorIs(t, forbidden, ErrSubredditBanned)
+	assert.NotErrorIs(t, forbidden, ErrSubredditPrivate)
+}
+
 func TestGetNewPosts(t *testing.T) {
 	cases := []struct {
 		name             string
@@ -105,7 +330,7 @@ func TestGetNewPosts(t *testing.T) {
 		tp := &TokenPollerMock{}
 		cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", tp)
 
-		expectedResponse := &api.NewPostsResponse{}
+		expectedResponse := &api.Listing{}
 		json.NewDecoder(bytes.NewBuffer(responseBytes)).Decode(expectedResponse)
 
 		actualResponse, actualErr := cl.GetNewPosts(context.Background(), "golang", "t3_151rq7s")
@@ -149,7 +374,7 @@ func TestSavePost(t *testing.T) {
 		tp := &TokenPollerMock{}
 		cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", tp)
 
-		actualErr := cl.SavePost(context.Background(), "postName")
+		actualErr := cl.SavePost(context.Background(), "postName", "")
 
 		if c.name == "success" {
 			assert.Nil(t, actualErr)
@@ -162,6 +387,64 @@ func TestSavePost(t *testing.T) {
 
 }
 
+func TestSavePostAppOnly(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	tp := &TokenPollerMock{appOnly: true}
+	cl := NewClient(ts.URL, "/dummy", "/dummy", "dummy", tp)
+
+	actualErr := cl.SavePost(context.Background(), "postName", "")
+
+	var userContextErr *UserContextError
+	assert.ErrorAs(t, actualErr, &userContextErr)
+	assert.Equal(t, "SavePost", userContextErr.Method)
+	assert.Equal(t, 0, requests)
+}
+
+func TestVerifyScopes(t *testing.T) {
+	cases := []struct {
+		name          string
+		tp            *TokenPollerMock
+		expectedScope string
+	}{
+		{
+			"unknownScopes",
+			&TokenPollerMock{},
+			"",
+		},
+		{
+			"allScopes",
+			&TokenPollerMock{scopes: []string{"*"}},
+			"",
+		},
+		{
+			"readOnly",
+			&TokenPollerMock{scopes: []string{"identity", "read"}},
+			"save",
+		},
+	}
+	for _, c := range cases {
+		cl := NewClient("dummy", "/dummy", "/dummy", "dummy", c.tp)
+		err := cl.VerifyScopes("GetNewPosts", "SavePost")
+		if c.expectedScope == "" {
+			assert.Nil(t, err, c.name)
+			continue
+		}
+		var scopeErr *ScopeError
+		assert.ErrorAs(t, err, &scopeErr, c.name)
+		assert.Equal(t, c.expectedScope, scopeErr.Scope, c.name)
+		assert.Equal(t, "SavePost", scopeErr.Method, c.name)
+	}
+
+	assert.NotNil(t, NewClient("dummy", "/dummy", "/dummy", "dummy", &TokenPollerMock{}).VerifyScopes("Unknown"))
+}
+
 func readFile(path string, t *testing.T) (b []byte) {
 	if path == "" {
 		return []byte("{}")
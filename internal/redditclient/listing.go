@@ -0,0 +1,139 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultListingLimit = 25
+	maxListingLimit     = 100
+)
+
+// listingIterator walks listing pages following after cursor, or before cursor if it's set in options
+type listingIterator struct {
+	cl       *rateLimitedClient
+	method   string // API method name used for scope checks
+	url      string
+	params   map[string]string // endpoint specific params
+	opts     api.ListingOptions
+	backward bool
+	inbox    bool // pages are decoded as api.InboxListing
+	after    string
+	before   string
+	count    int // number of things already seen
+	page     *api.Listing
+	err      error
+	done     bool
+}
+
+func (cl *rateLimitedClient) newListingIterator(method string, url string, params map[string]string,
+	opts api.ListingOptions) (it *listingIterator) {
+	it = &listingIterator{
+		cl:       cl,
+		method:   method,
+		url:      url,
+		params:   params,
+		opts:     opts,
+		backward: opts.Before != "",
+		after:    opts.After,
+		before:   opts.Before,
+	}
+	return it
+}
+
+// Next fetches next page, it returns false when listing is exhausted, the bound is reached or error occurred
+func (it *listingIterator) Next(ctx context.Context) bool {
+	if it.done || it.err != nil {
+		return false
+	}
+	limit := it.opts.Limit
+	if limit <= 0 {
+		limit = defaultListingLimit
+	}
+	if limit > maxListingLimit {
+		limit = maxListingLimit
+	}
+	if it.opts.Max > 0 && it.opts.Max-it.count < limit {
+		limit = it.opts.Max - it.count
+	}
+
+	params := make(map[string]string)
+	for k, v := range it.params {
+		params[k] = v
+	}
+	params["limit"] = strconv.Itoa(limit)
+	if it.count > 0 {
+		params["count"] = strconv.Itoa(it.count)
+	}
+	if it.backward {
+		params["before"] = it.before
+	} else if it.after != "" {
+		params["after"] = it.after
+	}
+
+	page, err := it.cl.getListing(ctx, it.method, it.url, params, it.inbox)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	n := len(page.Children)
+	if n == 0 {
+		it.done = true
+		return false
+	}
+	it.page = page
+	it.count += n
+	it.after, it.before = page.After, page.Before
+	if it.backward && it.before == "" || !it.backward && it.after == "" {
+		it.done = true
+	}
+	if it.opts.Max > 0 && it.count >= it.opts.Max {
+		it.done = true
+	}
+	return true
+}
+
+func (it *listingIterator) Page() *api.Listing {
+	return it.page
+}
+
+func (it *listingIterator) Count() int {
+	return it.count
+}
+
+func (it *listingIterator) Err() error {
+	return it.err
+}
+
+// getListing requests single listing page
+func (cl *rateLimitedClient) getListing(ctx context.Context, method string, url string,
+	params map[string]string, inbox bool) (listing *api.Listing, err error) {
+	err = cl.checkMethod(method)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cl.sendApiRequest(ctx, http.MethodGet, url, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if inbox {
+		inboxListing := &api.InboxListing{}
+		err = json.NewDecoder(resp.Body).Decode(inboxListing)
+		listing = &inboxListing.Listing
+	} else {
+		listing = &api.Listing{}
+		err = json.NewDecoder(resp.Body).Decode(listing)
+	}
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling listing response: url=%v: %w", url, err)
+		return nil, err
+	}
+	return listing, nil
+}
@@ -0,0 +1,150 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// listingHandler serves total links t3_0..t3_<total-1> paginated by after cursor
+func listingHandler(total int, requests *[]map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		*requests = append(*requests, map[string]string{
+			"limit": q.Get("limit"),
+			"after": q.Get("after"),
+			"count": q.Get("count"),
+		})
+		start := 0
+		if after := q.Get("after"); after != "" {
+			fmt.Sscanf(after, "t3_%d", &start)
+			start++
+		}
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		end := start + limit
+		if end > total {
+			end = total
+		}
+		children := make([]map[string]interface{}, 0)
+		for i := start; i < end; i++ {
+			children = append(children, map[string]interface{}{
+				"kind": api.KindLink,
+				"data": map[string]interface{}{"name": fmt.Sprintf("t3_%d", i)},
+			})
+		}
+		after := ""
+		if end < total {
+			after = fmt.Sprintf("t3_%d", end-1)
+		}
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"kind": api.KindListing,
+			"data": map[string]interface{}{"after": after, "children": children},
+		})
+	}
+}
+
+func TestListingIterator(t *testing.T) {
+	cases := []struct {
+		name             string
+		total            int
+		opts             api.ListingOptions
+		expectedCount    int
+		expectedRequests []map[string]string
+	}{
+		{
+			"defaultLimit",
+			30,
+			api.ListingOptions{},
+			30,
+			[]map[string]string{
+				{"limit": "25", "after": "", "count": ""},
+				{"limit": "25", "after": "t3_24", "count": "25"},
+			},
+		},
+		{
+			"limitClamped",
+			150,
+			api.ListingOptions{Limit: 500},
+			150,
+			[]map[string]string{
+				{"limit": "100", "after": "", "count": ""},
+				{"limit": "100", "after": "t3_99", "count": "100"},
+			},
+		},
+		{
+			"maxBound",
+			100,
+			api.ListingOptions{Limit: 10, Max: 15},
+			15,
+			[]map[string]string{
+				{"limit": "10", "after": "", "count": ""},
+				{"limit": "5", "after": "t3_9", "count": "10"},
+			},
+		},
+		{
+			"startAfter",
+			20,
+			api.ListingOptions{Limit: 10, After: "t3_4"},
+			15,
+			[]map[string]string{
+				{"limit": "10", "after": "t3_4", "count": ""},
+				{"limit": "10", "after": "t3_14", "count": "10"},
+			},
+		},
+		{
+			"empty",
+			0,
+			api.ListingOptions{},
+			0,
+			[]map[string]string{
+				{"limit": "25", "after": "", "count": ""},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests []map[string]string
+			ts := httptest.NewServer(listingHandler(c.total, &requests))
+			defer ts.Close()
+
+			cl := NewClient(ts.URL, "/new", "/dummy", "dummy", &TokenPollerMock{})
+			it := cl.NewPosts("golang", c.opts)
+			names := make([]string, 0)
+			for it.Next(context.Background()) {
+				for _, l := range it.Page().Links() {
+					names = append(names, l.Name)
+				}
+			}
+
+			assert.Nil(t, it.Err())
+			assert.Equal(t, c.expectedCount, len(names))
+			assert.Equal(t, c.expectedCount, it.Count())
+			assert.Equal(t, c.expectedRequests, requests)
+		})
+	}
+}
+
+func TestListingIteratorError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	cl := NewClient(ts.URL, "/new", "/dummy", "dummy", &TokenPollerMock{})
+	it := cl.NewPosts("golang", api.ListingOptions{})
+
+	assert.False(t, it.Next(context.Background()))
+	assert.NotNil(t, it.Err())
+	assert.False(t, it.Next(context.Background()))
+}
@@ -0,0 +1,65 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	meUrl      = "/api/v1/me"
+	myKarmaUrl = "/api/v1/me/karma"
+	myPrefsUrl = "/api/v1/me/prefs"
+)
+
+type karmaResponse struct {
+	Data []*api.SubredditKarma `json:"data"`
+}
+
+func (cl *rateLimitedClient) Me(ctx context.Context) (account *api.Account, err error) {
+	account = &api.Account{}
+	err = cl.getJson(ctx, "Me", cl.host+meUrl, nil, account)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// MyKarma returns karma breakdown of the authorized user per subreddit
+func (cl *rateLimitedClient) MyKarma(ctx context.Context) (karma []*api.SubredditKarma, err error) {
+	var resp karmaResponse
+	err = cl.getJson(ctx, "MyKarma", cl.host+myKarmaUrl, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (cl *rateLimitedClient) MyPrefs(ctx context.Context) (prefs api.Prefs, err error) {
+	err = cl.getJson(ctx, "MyPrefs", cl.host+myPrefsUrl, nil, &prefs)
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (cl *rateLimitedClient) PatchMyPrefs(ctx context.Context, prefs api.Prefs) (updated api.Prefs, err error) {
+	err = cl.checkMethod("PatchMyPrefs")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cl.sendJsonRequest(ctx, http.MethodPatch, cl.host+myPrefsUrl, prefs)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&updated)
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling prefs response: %w", err)
+		return nil, err
+	}
+	return updated, nil
+}
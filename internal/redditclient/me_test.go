@@ -0,0 +1,70 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMe(t *testing.T) {
+	var patch api.Prefs
+	var contentType string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		switch {
+		case r.URL.Path == meUrl:
+			w.Write([]byte(`{"id": "abc", "name": "dmmakbot", "link_karma": 1, "comment_karma": 2, "total_karma": 3, "created_utc": 1400000000.0}`))
+		case r.URL.Path == myKarmaUrl:
+			w.Write([]byte(`{"kind": "KarmaList", "data": [{"sr": "golang", "comment_karma": 20, "link_karma": 10}]}`))
+		case r.URL.Path == myPrefsUrl && r.Method == http.MethodGet:
+			w.Write([]byte(`{"over_18": false, "lang": "en", "num_comments": 200}`))
+		case r.URL.Path == myPrefsUrl && r.Method == http.MethodPatch:
+			contentType = r.Header.Get("Content-Type")
+			b, _ := io.ReadAll(r.Body)
+			json.Unmarshal(b, &patch)
+			w.Write([]byte(`{"over_18": true, "lang": "en", "num_comments": 200}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+	cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+	ctx := context.Background()
+
+	me, err := cl.Me(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "dmmakbot", me.Name)
+	assert.Equal(t, 3, me.TotalKarma)
+
+	karma, err := cl.MyKarma(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []*api.SubredditKarma{{Subreddit: "golang", LinkKarma: 10, CommentKarma: 20}}, karma)
+
+	prefs, err := cl.MyPrefs(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, api.Prefs{"over_18": false, "lang": "en", "num_comments": float64(200)}, prefs)
+
+	updated, err := cl.PatchMyPrefs(ctx, api.Prefs{"over_18": true})
+	assert.Nil(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, api.Prefs{"over_18": true}, patch)
+	assert.Equal(t, true, updated["over_18"])
+}
+
+func TestMeAppOnly(t *testing.T) {
+	cl := NewClient("http://localhost", "/new", "/api/save", "dummy", &TokenPollerMock{appOnly: true})
+
+	_, err := cl.Me(context.Background())
+
+	var userContextErr *UserContextError
+	assert.ErrorAs(t, err, &userContextErr)
+}
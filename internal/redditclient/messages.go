@@ -0,0 +1,72 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"fmt"
+	"strings"
+)
+
+const (
+	messageUrl       = "/message/"
+	composeUrl       = "/api/compose"
+	readMessageUrl   = "/api/read_message"
+	unreadMessageUrl = "/api/unread_message"
+	deleteMessageUrl = "/api/del_msg"
+)
+
+// Inbox walks messages of the box, one of api.Inbox* values. Messages aren't marked as read.
+func (cl *rateLimitedClient) Inbox(box string, opts api.ListingOptions) api.ListingIterator {
+	params := make(map[string]string)
+	params["mark"] = "false"
+	it := cl.newListingIterator("Inbox", cl.host+messageUrl+box, params, opts)
+	it.inbox = true
+	switch box {
+	case api.InboxAll, api.InboxUnread, api.InboxSent, api.InboxMentions, api.InboxCommentReplies:
+	default:
+		it.err = fmt.Errorf("unknown message box %q", box)
+	}
+	return it
+}
+
+// Compose sends private message to the user, or to subreddit moderators if recipient is /r/subreddit
+func (cl *rateLimitedClient) Compose(ctx context.Context, to string, subject string, markdown string) (err error) {
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["to"] = to
+	params["subject"] = subject
+	params["text"] = markdown
+	return cl.postAction(ctx, "Compose", cl.host+composeUrl, params)
+}
+
+// ReplyToMessage replies to the private message with markdown text and returns the created message
+func (cl *rateLimitedClient) ReplyToMessage(ctx context.Context, parent string, markdown string) (message *api.Message, err error) {
+	thing, err := cl.postUserText(ctx, "ReplyToMessage", cl.host+commentUrl, parent, markdown)
+	if err != nil {
+		return nil, err
+	}
+	message, ok := thing.Data.(*api.Message)
+	if !ok {
+		err = fmt.Errorf("unexpected thing kind %q in message reply response: parent=%v", thing.Kind, parent)
+		return nil, err
+	}
+	return message, nil
+}
+
+func (cl *rateLimitedClient) MarkRead(ctx context.Context, names ...string) (err error) {
+	params := make(map[string]string)
+	params["id"] = strings.Join(names, ",")
+	return cl.postAction(ctx, "MarkRead", cl.host+readMessageUrl, params)
+}
+
+func (cl *rateLimitedClient) MarkUnread(ctx context.Context, names ...string) (err error) {
+	params := make(map[string]string)
+	params["id"] = strings.Join(names, ",")
+	return cl.postAction(ctx, "MarkUnread", cl.host+unreadMessageUrl, params)
+}
+
+func (cl *rateLimitedClient) DeleteMessage(ctx context.Context, name string) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.postAction(ctx, "DeleteMessage", cl.host+deleteMessageUrl, params)
+}
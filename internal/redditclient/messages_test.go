@@ -0,0 +1,96 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInbox(t *testing.T) {
+	responseBytes := readFile("../../testdata/api/inboxListing.json", t)
+	var query string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Path + "?" + r.URL.RawQuery
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		w.Write(responseBytes)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+	it := cl.Inbox(api.InboxUnread, api.ListingOptions{Limit: 10})
+
+	assert.True(t, it.Next(context.Background()))
+	assert.Nil(t, it.Err())
+	assert.Equal(t, "/message/unread?limit=10&mark=false", query)
+	messages := it.Page().Messages()
+	assert.Len(t, messages, 2)
+	assert.True(t, messages[1].WasComment)
+
+	it = cl.Inbox("drafts", api.ListingOptions{})
+	assert.False(t, it.Next(context.Background()))
+	assert.NotNil(t, it.Err())
+}
+
+func TestMessageActions(t *testing.T) {
+	cases := []struct {
+		name          string
+		action        func(cl api.RedditAPIClient) error
+		expectedQuery string
+	}{
+		{
+			"compose",
+			func(cl api.RedditAPIClient) error {
+				return cl.Compose(context.Background(), "gopher42", "hello", "**hi**")
+			},
+			"/api/compose?api_type=json&subject=hello&text=%2A%2Ahi%2A%2A&to=gopher42",
+		},
+		{
+			"markRead",
+			func(cl api.RedditAPIClient) error {
+				return cl.MarkRead(context.Background(), "t4_1x2y3z", "t1_jsb1aaa")
+			},
+			"/api/read_message?id=t4_1x2y3z%2Ct1_jsb1aaa",
+		},
+		{
+			"markUnread",
+			func(cl api.RedditAPIClient) error {
+				return cl.MarkUnread(context.Background(), "t4_1x2y3z")
+			},
+			"/api/unread_message?id=t4_1x2y3z",
+		},
+		{
+			"delete",
+			func(cl api.RedditAPIClient) error {
+				return cl.DeleteMessage(context.Background(), "t4_1x2y3z")
+			},
+			"/api/del_msg?id=t4_1x2y3z",
+		},
+	}
+
+	for _, c := range cases {
+		var query string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			query = r.URL.Path + "?" + r.PostForm.Encode()
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write([]byte(`{"json": {"errors": []}}`))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+		actualErr := c.action(cl)
+
+		assert.Nil(t, actualErr, c.name)
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		ts.Close()
+	}
+}
@@ -0,0 +1,90 @@
+package redditclient
+
+import (
+	"dmmak/redditapi/internal/api"
+	"errors"
+	"fmt"
+)
+
+// methodSpec declares auth requirements of API method
+type methodSpec struct {
+	scope       string
+	userContext bool // method acts on behalf of a user and isn't available with app-only tokens
+}
+
+var methods = map[string]methodSpec{
+	"GetNewPosts":         {scope: api.ScopeRead},
+	"SavePost":            {scope: api.ScopeSave, userContext: true},
+	"GetComments":         {scope: api.ScopeRead},
+	"ExpandMore":          {scope: api.ScopeRead},
+	"HotPosts":            {scope: api.ScopeRead},
+	"TopPosts":            {scope: api.ScopeRead},
+	"ControversialPosts":  {scope: api.ScopeRead},
+	"RisingPosts":         {scope: api.ScopeRead},
+	"Search":              {scope: api.ScopeRead},
+	"UnsavePost":          {scope: api.ScopeSave, userContext: true},
+	"Hide":                {scope: api.ScopeReport, userContext: true},
+	"Unhide":              {scope: api.ScopeReport, userContext: true},
+	"Vote":                {scope: api.ScopeVote, userContext: true},
+	"MarkNSFW":            {scope: api.ScopeModPosts, userContext: true},
+	"Spoiler":             {scope: api.ScopeModPosts, userContext: true},
+	"Report":              {scope: api.ScopeReport, userContext: true},
+	"Submit":              {scope: api.ScopeSubmit, userContext: true},
+	"Comment":             {scope: api.ScopeSubmit, userContext: true},
+	"EditUserText":        {scope: api.ScopeEdit, userContext: true},
+	"Delete":              {scope: api.ScopeEdit, userContext: true},
+	"Inbox":               {scope: api.ScopePrivateMessages, userContext: true},
+	"Compose":             {scope: api.ScopePrivateMessages, userContext: true},
+	"ReplyToMessage":      {scope: api.ScopePrivateMessages, userContext: true},
+	"MarkRead":            {scope: api.ScopePrivateMessages, userContext: true},
+	"MarkUnread":          {scope: api.ScopePrivateMessages, userContext: true},
+	"DeleteMessage":       {scope: api.ScopePrivateMessages, userContext: true},
+	"SubredditAbout":      {scope: api.ScopeRead},
+	"SubredditSidebar":    {scope: api.ScopeRead},
+	"SubredditRules":      {scope: api.ScopeRead},
+	"SubredditModerators": {scope: api.ScopeRead},
+	"UserAbout":           {scope: api.ScopeRead},
+	"UserSubmitted":       {scope: api.ScopeHistory},
+	"UserComments":        {scope: api.ScopeHistory},
+	"UserSaved":           {scope: api.ScopeHistory, userContext: true},
+	"UserUpvoted":         {scope: api.ScopeHistory, userContext: true},
+	"Me":                  {scope: api.ScopeIdentity, userContext: true},
+	"MyKarma":             {scope: api.ScopeMySubreddits, userContext: true},
+	"MyPrefs":             {scope: api.ScopeIdentity, userContext: true},
+	"PatchMyPrefs":        {scope: api.ScopeAccount, userContext: true},
+	"Approve":             {scope: api.ScopeModPosts, userContext: true},
+	"Remove":              {scope: api.ScopeModPosts, userContext: true},
+	"Lock":                {scope: api.ScopeModPosts, userContext: true},
+	"Unlock":              {scope: api.ScopeModPosts, userContext: true},
+	"Sticky":              {scope: api.ScopeModPosts, userContext: true},
+	"Distinguish":         {scope: api.ScopeModPosts, userContext: true},
+	"SetContestMode":      {scope: api.ScopeModPosts, userContext: true},
+	"SetSuggestedSort":    {scope: api.ScopeModPosts, userContext: true},
+	"IgnoreReports":       {scope: api.ScopeModPosts, userContext: true},
+}
+
+// checkMethod fails fast if the method can't be called with current auth token
+func (cl *rateLimitedClient) checkMethod(method string) (err error) {
+	spec, ok := methods[method]
+	if !ok {
+		err = fmt.Errorf("unknown API method %v", method)
+		return err
+	}
+	if spec.userContext && cl.authTokenPoller.AppOnly() {
+		return &UserContextError{Method: method}
+	}
+	if !cl.authTokenPoller.Status().Allows(spec.scope) {
+		return &ScopeError{Method: method, Scope: spec.scope}
+	}
+	return nil
+}
+
+func (cl *rateLimitedClient) VerifyScopes(methods ...string) (err error) {
+	var errs []error
+	for _, method := range methods {
+		if err := cl.checkMethod(method); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
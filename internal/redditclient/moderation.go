@@ -0,0 +1,128 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	approveUrl         = "/api/approve"
+	removeUrl          = "/api/remove"
+	lockUrl            = "/api/lock"
+	unlockUrl          = "/api/unlock"
+	stickyUrl          = "/api/set_subreddit_sticky"
+	distinguishUrl     = "/api/distinguish"
+	contestModeUrl     = "/api/set_contest_mode"
+	suggestedSortUrl   = "/api/set_suggested_sort"
+	ignoreReportsUrl   = "/api/ignore_reports"
+	unignoreReportsUrl = "/api/unignore_reports"
+)
+
+const (
+	clearSuggestedSort = "blank" // suggested sort value which clears it
+	maxStickySlot      = 4
+)
+
+func (cl *rateLimitedClient) Approve(ctx context.Context, name string) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.modAction(ctx, "Approve", cl.host+approveUrl, params)
+}
+
+// Remove removes the post or comment, spam trains subreddit spam filter
+func (cl *rateLimitedClient) Remove(ctx context.Context, name string, spam bool) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	params["spam"] = strconv.FormatBool(spam)
+	return cl.modAction(ctx, "Remove", cl.host+removeUrl, params)
+}
+
+func (cl *rateLimitedClient) Lock(ctx context.Context, name string) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.modAction(ctx, "Lock", cl.host+lockUrl, params)
+}
+
+func (cl *rateLimitedClient) Unlock(ctx context.Context, name string) (err error) {
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.modAction(ctx, "Unlock", cl.host+unlockUrl, params)
+}
+
+// Sticky pins the post to the subreddit top or unpins it. Slot is 1 to 4, zero lets Reddit choose the slot.
+func (cl *rateLimitedClient) Sticky(ctx context.Context, name string, state bool, slot int) (err error) {
+	if slot < 0 || slot > maxStickySlot {
+		err = fmt.Errorf("invalid sticky slot %v", slot)
+		return err
+	}
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["id"] = name
+	params["state"] = strconv.FormatBool(state)
+	if slot > 0 {
+		params["num"] = strconv.Itoa(slot)
+	}
+	return cl.modAction(ctx, "Sticky", cl.host+stickyUrl, params)
+}
+
+// Distinguish marks the post or comment as made by moderator, sticky pins moderator comment to the top
+func (cl *rateLimitedClient) Distinguish(ctx context.Context, name string, how string, sticky bool) (err error) {
+	switch how {
+	case api.DistinguishYes, api.DistinguishNo, api.DistinguishAdmin, api.DistinguishSpecial:
+	default:
+		err = fmt.Errorf("invalid distinguish value %q", how)
+		return err
+	}
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["id"] = name
+	params["how"] = how
+	if sticky {
+		params["sticky"] = "true"
+	}
+	return cl.modAction(ctx, "Distinguish", cl.host+distinguishUrl, params)
+}
+
+func (cl *rateLimitedClient) SetContestMode(ctx context.Context, name string, enabled bool) (err error) {
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["id"] = name
+	params["state"] = strconv.FormatBool(enabled)
+	return cl.modAction(ctx, "SetContestMode", cl.host+contestModeUrl, params)
+}
+
+// SetSuggestedSort sets default comment sort of the post, empty sort clears it
+func (cl *rateLimitedClient) SetSuggestedSort(ctx context.Context, name string, sort string) (err error) {
+	if sort == "" {
+		sort = clearSuggestedSort
+	}
+	params := make(map[string]string)
+	params["api_type"] = "json"
+	params["id"] = name
+	params["sort"] = sort
+	return cl.modAction(ctx, "SetSuggestedSort", cl.host+suggestedSortUrl, params)
+}
+
+// IgnoreReports stops or resumes notifying moderators about reports of the post or comment
+func (cl *rateLimitedClient) IgnoreReports(ctx context.Context, name string, ignore bool) (err error) {
+	url := cl.host + ignoreReportsUrl
+	if !ignore {
+		url = cl.host + unignoreReportsUrl
+	}
+	params := make(map[string]string)
+	params["id"] = name
+	return cl.modAction(ctx, "IgnoreReports", url, params)
+}
+
+// modAction sends moderation request, rejections because of missing moderator permissions
+// are returned as *ModPermissionError
+func (cl *rateLimitedClient) modAction(ctx context.Context, method string, url string, params map[string]string) (err error) {
+	err = cl.postAction(ctx, method, url, params)
+	if errors.Is(err, ErrForbidden) {
+		return &ModPermissionError{Method: method, Name: params["id"], Err: err}
+	}
+	return err
+}
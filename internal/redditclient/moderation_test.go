@@ -0,0 +1,178 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeration(t *testing.T) {
+	cases := []struct {
+		name          string
+		action        func(cl api.RedditAPIClient) error
+		expectedQuery string
+		expectedErr   bool
+	}{
+		{
+			"approve",
+			func(cl api.RedditAPIClient) error {
+				return cl.Approve(context.Background(), "t3_abc")
+			},
+			"/api/approve?id=t3_abc",
+			false,
+		},
+		{
+			"removeSpam",
+			func(cl api.RedditAPIClient) error {
+				return cl.Remove(context.Background(), "t1_abc", true)
+			},
+			"/api/remove?id=t1_abc&spam=true",
+			false,
+		},
+		{
+			"lock",
+			func(cl api.RedditAPIClient) error {
+				return cl.Lock(context.Background(), "t3_abc")
+			},
+			"/api/lock?id=t3_abc",
+			false,
+		},
+		{
+			"unlock",
+			func(cl api.RedditAPIClient) error {
+				return cl.Unlock(context.Background(), "t3_abc")
+			},
+			"/api/unlock?id=t3_abc",
+			false,
+		},
+		{
+			"stickyToSlot",
+			func(cl api.RedditAPIClient) error {
+				return cl.Sticky(context.Background(), "t3_abc", true, 2)
+			},
+			"/api/set_subreddit_sticky?api_type=json&id=t3_abc&num=2&state=true",
+			false,
+		},
+		{
+			"unsticky",
+			func(cl api.RedditAPIClient) error {
+				return cl.Sticky(context.Background(), "t3_abc", false, 0)
+			},
+			"/api/set_subreddit_sticky?api_type=json&id=t3_abc&state=false",
+			false,
+		},
+		{
+			"invalidStickySlot",
+			func(cl api.RedditAPIClient) error {
+				return cl.Sticky(context.Background(), "t3_abc", true, 5)
+			},
+			"",
+			true,
+		},
+		{
+			"distinguishSticky",
+			func(cl api.RedditAPIClient) error {
+				return cl.Distinguish(context.Background(), "t1_abc", api.DistinguishYes, true)
+			},
+			"/api/distinguish?api_type=json&how=yes&id=t1_abc&sticky=true",
+			false,
+		},
+		{
+			"invalidDistinguish",
+			func(cl api.RedditAPIClient) error {
+				return cl.Distinguish(context.Background(), "t1_abc", "maybe", false)
+			},
+			"",
+			true,
+		},
+		{
+			"contestMode",
+			func(cl api.RedditAPIClient) error {
+				return cl.SetContestMode(context.Background(), "t3_abc", true)
+			},
+			"/api/set_contest_mode?api_type=json&id=t3_abc&state=true",
+			false,
+		},
+		{
+			"suggestedSort",
+			func(cl api.RedditAPIClient) error {
+				return cl.SetSuggestedSort(context.Background(), "t3_abc", api.CommentSortQA)
+			},
+			"/api/set_suggested_sort?api_type=json&id=t3_abc&sort=qa",
+			false,
+		},
+		{
+			"clearSuggestedSort",
+			func(cl api.RedditAPIClient) error {
+				return cl.SetSuggestedSort(context.Background(), "t3_abc", "")
+			},
+			"/api/set_suggested_sort?api_type=json&id=t3_abc&sort=blank",
+			false,
+		},
+		{
+			"ignoreReports",
+			func(cl api.RedditAPIClient) error {
+				return cl.IgnoreReports(context.Background(), "t3_abc", true)
+			},
+			"/api/ignore_reports?id=t3_abc",
+			false,
+		},
+		{
+			"unignoreReports",
+			func(cl api.RedditAPIClient) error {
+				return cl.IgnoreReports(context.Background(), "t3_abc", false)
+			},
+			"/api/unignore_reports?id=t3_abc",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		var query string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			query = r.URL.Path + "?" + r.PostForm.Encode()
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write([]byte(`{}`))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+		actualErr := c.action(cl)
+
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		if c.expectedErr {
+			assert.NotNil(t, actualErr, c.name)
+		} else {
+			assert.Nil(t, actualErr, c.name)
+		}
+		ts.Close()
+	}
+}
+
+func TestModerationNotModerator(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Forbidden", "error": 403}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+	cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+
+	err := cl.Remove(context.Background(), "t3_abc", false)
+
+	var permErr *ModPermissionError
+	assert.ErrorAs(t, err, &permErr)
+	assert.Equal(t, "Remove", permErr.Method)
+	assert.Equal(t, "t3_abc", permErr.Name)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
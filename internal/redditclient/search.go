@@ -0,0 +1,49 @@
+package redditclient
+
+import (
+	"dmmak/redditapi/internal/api"
+	"fmt"
+	"strings"
+)
+
+const searchUrl = "/search"
+
+// Search walks search results, site-wide or within opts.Subreddit. Depending on opts.Types
+// result pages contain links, subreddits and accounts.
+func (cl *rateLimitedClient) Search(query string, opts api.SearchOptions) api.ListingIterator {
+	url := cl.host + searchUrl
+	params := make(map[string]string)
+	params["q"] = query
+	if opts.Subreddit != "" {
+		url = cl.host + "/r/" + opts.Subreddit + searchUrl
+		params["restrict_sr"] = "true"
+	}
+	err := setSearchParams(params, opts)
+	it := cl.newListingIterator("Search", url, params, opts.ListingOptions)
+	it.err = err
+	return it
+}
+
+// setSearchParams sets sort, result types and time period, unknown values are rejected
+func setSearchParams(params map[string]string, opts api.SearchOptions) (err error) {
+	switch opts.Sort {
+	case "":
+	case api.SearchSortRelevance, api.SearchSortHot, api.SearchSortTop, api.SearchSortNew, api.SearchSortComments:
+		params["sort"] = opts.Sort
+	default:
+		err = fmt.Errorf("unknown search sort %q", opts.Sort)
+		return err
+	}
+	for _, t := range opts.Types {
+		switch t {
+		case api.SearchTypeLink, api.SearchTypeSubreddit, api.SearchTypeUser:
+		default:
+			err = fmt.Errorf("unknown search result type %q", t)
+			return err
+		}
+	}
+	if len(opts.Types) > 0 {
+		params["type"] = strings.Join(opts.Types, ",")
+	}
+	return setTimeParam(params, "Search", opts.Time)
+}
@@ -0,0 +1,91 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	responseBytes := readFile("../../testdata/redditclient/successNewPostResponse.json", t)
+	cases := []struct {
+		name          string
+		opts          api.SearchOptions
+		expectedQuery string
+		expectedErr   bool
+	}{
+		{
+			"siteWide",
+			api.SearchOptions{ListingOptions: api.ListingOptions{Limit: 100}},
+			"/search?limit=100&q=generics",
+			false,
+		},
+		{
+			"subreddit",
+			api.SearchOptions{
+				ListingOptions: api.ListingOptions{Limit: 50, After: "t3_151rq7s"},
+				Subreddit:      "golang",
+				Sort:           api.SearchSortNew,
+				Time:           api.TimeWeek,
+				Types:          []string{api.SearchTypeLink, api.SearchTypeSubreddit},
+			},
+			"/r/golang/search?after=t3_151rq7s&limit=50&q=generics&restrict_sr=true&sort=new&t=week&type=link%2Csr",
+			false,
+		},
+		{
+			"unknownTimePeriod",
+			api.SearchOptions{Time: "decade"},
+			"",
+			true,
+		},
+		{
+			"unknownSort",
+			api.SearchOptions{Sort: "best"},
+			"",
+			true,
+		},
+		{
+			"unknownType",
+			api.SearchOptions{Types: []string{api.SearchTypeLink, "comment"}},
+			"",
+			true,
+		},
+		{
+			"userType",
+			api.SearchOptions{Sort: api.SearchSortRelevance, Types: []string{api.SearchTypeUser}},
+			"/search?limit=25&q=generics&sort=relevance&type=user",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		var query string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Path + "?" + r.URL.RawQuery
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write(responseBytes)
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/dummy", "dummy", &TokenPollerMock{})
+		it := cl.Search("generics", c.opts)
+		ok := it.Next(context.Background())
+
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		if c.expectedErr {
+			assert.False(t, ok, c.name)
+			assert.NotNil(t, it.Err(), c.name)
+		} else {
+			assert.True(t, ok, c.name)
+			assert.Nil(t, it.Err(), c.name)
+			assert.NotEmpty(t, it.Page().Links(), c.name)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,72 @@
+package redditclient
+
+import (
+	"dmmak/redditapi/internal/api"
+	"fmt"
+)
+
+const (
+	hotUrl           = "/hot"
+	topUrl           = "/top"
+	risingUrl        = "/rising"
+	controversialUrl = "/controversial"
+)
+
+// HotPosts walks hot posts of the subreddit, or of the front page if subreddit is empty.
+// Geo filter is a country code, e.g. "GLOBAL" or "US", and only applies to the front page,
+// so the iterator fails on first Next call if it's combined with the subreddit.
+func (cl *rateLimitedClient) HotPosts(subreddit string, geoFilter string, opts api.ListingOptions) api.ListingIterator {
+	params := make(map[string]string)
+	var err error
+	if geoFilter != "" && subreddit != "" {
+		err = fmt.Errorf("geo filter %q applies to the front page only, not to subreddit %q", geoFilter, subreddit)
+	} else if geoFilter != "" {
+		params["g"] = geoFilter
+	}
+	it := cl.newListingIterator("HotPosts", cl.sortedUrl(subreddit, hotUrl), params, opts)
+	it.err = err
+	return it
+}
+
+// TopPosts walks top posts of the subreddit, or of the front page if subreddit is empty, for the time period
+func (cl *rateLimitedClient) TopPosts(subreddit string, period string, opts api.ListingOptions) api.ListingIterator {
+	return cl.newTimeFilteredIterator("TopPosts", cl.sortedUrl(subreddit, topUrl), period, opts)
+}
+
+// ControversialPosts walks controversial posts of the subreddit, or of the front page if subreddit is empty, for the time period
+func (cl *rateLimitedClient) ControversialPosts(subreddit string, period string, opts api.ListingOptions) api.ListingIterator {
+	return cl.newTimeFilteredIterator("ControversialPosts", cl.sortedUrl(subreddit, controversialUrl), period, opts)
+}
+
+// RisingPosts walks rising posts of the subreddit, or of the front page if subreddit is empty
+func (cl *rateLimitedClient) RisingPosts(subreddit string, opts api.ListingOptions) api.ListingIterator {
+	return cl.newListingIterator("RisingPosts", cl.sortedUrl(subreddit, risingUrl), nil, opts)
+}
+
+func (cl *rateLimitedClient) sortedUrl(subreddit string, sort string) string {
+	if subreddit == "" {
+		return cl.host + sort
+	}
+	return cl.host + "/r/" + subreddit + sort
+}
+
+// newTimeFilteredIterator returns iterator which fails on first Next call if the time period is unknown
+func (cl *rateLimitedClient) newTimeFilteredIterator(method string, url string, period string, opts api.ListingOptions) *listingIterator {
+	params := make(map[string]string)
+	err := setTimeParam(params, method, period)
+	it := cl.newListingIterator(method, url, params, opts)
+	it.err = err
+	return it
+}
+
+func setTimeParam(params map[string]string, method string, period string) (err error) {
+	switch period {
+	case "":
+	case api.TimeHour, api.TimeDay, api.TimeWeek, api.TimeMonth, api.TimeYear, api.TimeAll:
+		params["t"] = period
+	default:
+		err = fmt.Errorf("unknown time period %q for API method %v", period, method)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedPosts(t *testing.T) {
+	responseBytes := readFile("../../testdata/redditclient/successNewPostResponse.json", t)
+	cases := []struct {
+		name          string
+		iterator      func(cl api.RedditAPIClient) api.ListingIterator
+		expectedQuery string
+		expectedErr   bool
+	}{
+		{
+			"hot",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.HotPosts("golang", "", api.ListingOptions{Limit: 10})
+			},
+			"/r/golang/hot?limit=10",
+			false,
+		},
+		{
+			"hotFrontPageGeoFilter",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.HotPosts("", "US", api.ListingOptions{Limit: 10})
+			},
+			"/hot?g=US&limit=10",
+			false,
+		},
+		{
+			"hotSubredditGeoFilter",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.HotPosts("golang", "US", api.ListingOptions{Limit: 10})
+			},
+			"",
+			true,
+		},
+		{
+			"topOfTheDay",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.TopPosts("golang", api.TimeDay, api.ListingOptions{Limit: 10})
+			},
+			"/r/golang/top?limit=10&t=day",
+			false,
+		},
+		{
+			"controversialAllTime",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.ControversialPosts("golang", api.TimeAll, api.ListingOptions{Limit: 10})
+			},
+			"/r/golang/controversial?limit=10&t=all",
+			false,
+		},
+		{
+			"rising",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.RisingPosts("golang", api.ListingOptions{Limit: 10})
+			},
+			"/r/golang/rising?limit=10",
+			false,
+		},
+		{
+			"unknownTimePeriod",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.TopPosts("golang", "decade", api.ListingOptions{Limit: 10})
+			},
+			"",
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		var query string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Path + "?" + r.URL.RawQuery
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write(responseBytes)
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/dummy", "dummy", &TokenPollerMock{})
+		it := c.iterator(cl)
+		ok := it.Next(context.Background())
+
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		if c.expectedErr {
+			assert.False(t, ok, c.name)
+			assert.NotNil(t, it.Err(), c.name)
+		} else {
+			assert.True(t, ok, c.name)
+			assert.Nil(t, it.Err(), c.name)
+			assert.NotEmpty(t, it.Page().Links(), c.name)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const submitUrl = "/api/submit"
+
+type submitResponse struct {
+	Json struct {
+		Data api.Submitted `json:"data"`
+	} `json:"json"`
+}
+
+// Submit creates self, link or crosspost post. Reddit validation errors are returned as *APIError,
+// e.g. matching ErrSubredditNotAllowed or ErrAlreadySubmitted.
+func (cl *rateLimitedClient) Submit(ctx context.Context, r api.SubmitRequest) (post *api.Submitted, err error) {
+	err = cl.checkMethod("Submit")
+	if err != nil {
+		return nil, err
+	}
+	params := make(map[string]string)
+	switch r.Kind {
+	case api.SubmitSelf:
+		params["text"] = r.Text
+	case api.SubmitLink:
+		params["url"] = r.Url
+	case api.SubmitCrosspost:
+		params["crosspost_fullname"] = r.CrosspostName
+	default:
+		err = fmt.Errorf("unknown post kind %q", r.Kind)
+		return nil, err
+	}
+	params["api_type"] = "json"
+	params["kind"] = r.Kind
+	params["sr"] = r.Subreddit
+	params["title"] = r.Title
+	if r.FlairId != "" {
+		params["flair_id"] = r.FlairId
+	}
+	if r.FlairText != "" {
+		params["flair_text"] = r.FlairText
+	}
+	params["nsfw"] = strconv.FormatBool(r.NSFW)
+	params["spoiler"] = strconv.FormatBool(r.Spoiler)
+	params["sendreplies"] = strconv.FormatBool(r.SendReplies)
+	params["resubmit"] = strconv.FormatBool(r.Resubmit)
+
+	resp, err := cl.sendApiRequest(ctx, http.MethodPost, cl.host+submitUrl, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var submitted submitResponse
+	err = json.NewDecoder(resp.Body).Decode(&submitted)
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling submit response: subreddit=%v: %w", r.Subreddit, err)
+		return nil, err
+	}
+	if submitted.Json.Data.Name == "" {
+		err = fmt.Errorf("submit response has no post name: subreddit=%v", r.Subreddit)
+		return nil, err
+	}
+	return &submitted.Json.Data, nil
+}
@@ -0,0 +1,98 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmit(t *testing.T) {
+	successBody := `{"json": {"errors": [], "data": {"url": "https://www.reddit.com/r/golang/comments/abc/title/", "drafts_count": 0, "id": "abc", "name": "t3_abc"}}}`
+	cases := []struct {
+		name         string
+		request      api.SubmitRequest
+		responseBody string
+		expectedForm string
+		expectedPost *api.Submitted
+		expectedErr  error
+	}{
+		{
+			"self",
+			api.SubmitRequest{Subreddit: "golang", Kind: api.SubmitSelf, Title: "title", Text: "body", FlairId: "flair1", SendReplies: true},
+			successBody,
+			"api_type=json&flair_id=flair1&kind=self&nsfw=false&resubmit=false&sendreplies=true&spoiler=false&sr=golang&text=body&title=title",
+			&api.Submitted{Id: "abc", Name: "t3_abc", Url: "https://www.reddit.com/r/golang/comments/abc/title/"},
+			nil,
+		},
+		{
+			"link",
+			api.SubmitRequest{Subreddit: "golang", Kind: api.SubmitLink, Title: "title", Url: "https://go.dev", FlairText: "news", NSFW: true, Spoiler: true, Resubmit: true},
+			successBody,
+			"api_type=json&flair_text=news&kind=link&nsfw=true&resubmit=true&sendreplies=false&spoiler=true&sr=golang&title=title&url=https%3A%2F%2Fgo.dev",
+			&api.Submitted{Id: "abc", Name: "t3_abc", Url: "https://www.reddit.com/r/golang/comments/abc/title/"},
+			nil,
+		},
+		{
+			"crosspost",
+			api.SubmitRequest{Subreddit: "golang", Kind: api.SubmitCrosspost, Title: "title", CrosspostName: "t3_def"},
+			successBody,
+			"api_type=json&crosspost_fullname=t3_def&kind=crosspost&nsfw=false&resubmit=false&sendreplies=false&spoiler=false&sr=golang&title=title",
+			&api.Submitted{Id: "abc", Name: "t3_abc", Url: "https://www.reddit.com/r/golang/comments/abc/title/"},
+			nil,
+		},
+		{
+			"alreadySubmitted",
+			api.SubmitRequest{Subreddit: "golang", Kind: api.SubmitLink, Title: "title", Url: "https://go.dev"},
+			`{"json": {"errors": [["ALREADY_SUB", "that link has already been submitted", "url"]]}}`,
+			"api_type=json&kind=link&nsfw=false&resubmit=false&sendreplies=false&spoiler=false&sr=golang&title=title&url=https%3A%2F%2Fgo.dev",
+			nil,
+			ErrAlreadySubmitted,
+		},
+		{
+			"subredditNotAllowed",
+			api.SubmitRequest{Subreddit: "private", Kind: api.SubmitSelf, Title: "title"},
+			`{"json": {"errors": [["SUBREDDIT_NOTALLOWED", "you aren't allowed to post there.", "sr"]]}}`,
+			"api_type=json&kind=self&nsfw=false&resubmit=false&sendreplies=false&spoiler=false&sr=private&text=&title=title",
+			nil,
+			ErrSubredditNotAllowed,
+		},
+	}
+
+	for _, c := range cases {
+		var form string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			form = r.PostForm.Encode()
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write([]byte(c.responseBody))
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+		post, err := cl.Submit(context.Background(), c.request)
+
+		assert.Equal(t, c.expectedForm, form, c.name)
+		assert.Equal(t, c.expectedPost, post, c.name)
+		if c.expectedErr != nil {
+			assert.ErrorIs(t, err, c.expectedErr, c.name)
+		} else {
+			assert.Nil(t, err, c.name)
+		}
+		ts.Close()
+	}
+}
+
+func TestSubmitUnknownKind(t *testing.T) {
+	cl := NewClient("http://localhost", "/new", "/api/save", "dummy", &TokenPollerMock{})
+
+	post, err := cl.Submit(context.Background(), api.SubmitRequest{Subreddit: "golang", Kind: "video"})
+
+	assert.Nil(t, post)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,92 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	aboutUrl      = "/about"
+	rulesUrl      = "/about/rules"
+	moderatorsUrl = "/about/moderators"
+)
+
+type (
+	rulesResponse struct {
+		Rules []*api.Rule `json:"rules"`
+	}
+
+	moderatorsResponse struct {
+		Data struct {
+			Children []*api.Moderator `json:"children"`
+		} `json:"data"`
+	}
+)
+
+func (cl *rateLimitedClient) SubredditAbout(ctx context.Context, name string) (sub *api.Subreddit, err error) {
+	return cl.subredditAbout(ctx, "SubredditAbout", name)
+}
+
+// SubredditSidebar returns sidebar markdown of the subreddit
+func (cl *rateLimitedClient) SubredditSidebar(ctx context.Context, name string) (markdown string, err error) {
+	sub, err := cl.subredditAbout(ctx, "SubredditSidebar", name)
+	if err != nil {
+		return "", err
+	}
+	return sub.Description, nil
+}
+
+func (cl *rateLimitedClient) SubredditRules(ctx context.Context, name string) (rules []*api.Rule, err error) {
+	var resp rulesResponse
+	err = cl.getJson(ctx, "SubredditRules", cl.host+"/r/"+name+rulesUrl, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Rules, nil
+}
+
+func (cl *rateLimitedClient) SubredditModerators(ctx context.Context, name string) (mods []*api.Moderator, err error) {
+	var resp moderatorsResponse
+	err = cl.getJson(ctx, "SubredditModerators", cl.host+"/r/"+name+moderatorsUrl, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data.Children, nil
+}
+
+func (cl *rateLimitedClient) subredditAbout(ctx context.Context, method string, name string) (sub *api.Subreddit, err error) {
+	var thing api.Thing
+	err = cl.getJson(ctx, method, cl.host+"/r/"+name+aboutUrl, nil, &thing)
+	if err != nil {
+		return nil, err
+	}
+	// Reddit responds with search results instead of 404 for some unknown subreddits
+	sub, ok := thing.Data.(*api.Subreddit)
+	if !ok {
+		return nil, &SubredditError{Subreddit: name, Reason: "not found"}
+	}
+	return sub, nil
+}
+
+// getJson requests API method and decodes JSON response into v
+func (cl *rateLimitedClient) getJson(ctx context.Context, method string, url string, params map[string]string, v interface{}) (err error) {
+	err = cl.checkMethod(method)
+	if err != nil {
+		return err
+	}
+	resp, err := cl.sendApiRequest(ctx, http.MethodGet, url, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		err = fmt.Errorf("error while umarshalling %v response: url=%v: %w", method, url, err)
+		return err
+	}
+	return nil
+}
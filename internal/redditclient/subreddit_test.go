@@ -0,0 +1,137 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const aboutGolang = `{"kind": "t5", "data": {"id": "2rc7j", "name": "t5_2rc7j", "display_name": "golang", "description": "**sidebar**", "subreddit_type": "public", "quarantine": false, "created_utc": 1257400000.0}}`
+
+func subredditServer(responses map[string]string, statusCode int) *httptest.Server {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found", "error": 404}`))
+			return
+		}
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func TestSubredditMetadata(t *testing.T) {
+	ts := subredditServer(map[string]string{
+		"/r/golang/about": aboutGolang,
+		"/r/golang/about/rules": `{"rules": [{"kind": "link", "short_name": "Be kind", "description": "Be kind.", "violation_reason": "Unkind", "priority": 0, "created_utc": 1500000000.0}],
+			"site_rules": ["Spam"]}`,
+		"/r/golang/about/moderators": `{"kind": "UserList", "data": {"children": [{"name": "gopher42", "id": "t2_abc", "date": 1400000000.0, "mod_permissions": ["all"]}]}}`,
+	}, http.StatusOK)
+	defer ts.Close()
+	cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+	ctx := context.Background()
+
+	sub, err := cl.SubredditAbout(ctx, "golang")
+	assert.Nil(t, err)
+	assert.Equal(t, "t5_2rc7j", sub.Name)
+	assert.Equal(t, api.SubredditPublic, sub.SubredditType)
+
+	sidebar, err := cl.SubredditSidebar(ctx, "golang")
+	assert.Nil(t, err)
+	assert.Equal(t, "**sidebar**", sidebar)
+
+	rules, err := cl.SubredditRules(ctx, "golang")
+	assert.Nil(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "Be kind", rules[0].ShortName)
+	assert.Equal(t, time.Unix(1500000000, 0).UTC(), rules[0].Created)
+
+	mods, err := cl.SubredditModerators(ctx, "golang")
+	assert.Nil(t, err)
+	assert.Len(t, mods, 1)
+	assert.Equal(t, "gopher42", mods[0].Name)
+	assert.Equal(t, []string{"all"}, mods[0].ModPermissions)
+	assert.Equal(t, time.Unix(1400000000, 0).UTC(), mods[0].Added)
+}
+
+func TestVerifySubreddit(t *testing.T) {
+	cases := []struct {
+		name           string
+		aboutResponse  string
+		statusCode     int
+		expectedReason string
+	}{
+		{
+			"public",
+			aboutGolang,
+			http.StatusOK,
+			"",
+		},
+		{
+			"restricted",
+			`{"kind": "t5", "data": {"display_name": "golang", "subreddit_type": "restricted"}}`,
+			http.StatusOK,
+			"",
+		},
+		{
+			"quarantined",
+			`{"kind": "t5", "data": {"display_name": "golang", "subreddit_type": "public", "quarantine": true}}`,
+			http.StatusOK,
+			"quarantined",
+		},
+		{
+			"private",
+			`{"reason": "private", "message": "Forbidden", "error": 403}`,
+			http.StatusForbidden,
+			"private",
+		},
+		{
+			"employeesOnly",
+			`{"kind": "t5", "data": {"display_name": "golang", "subreddit_type": "employees_only"}}`,
+			http.StatusOK,
+			"employees_only",
+		},
+		{
+			"searchResults",
+			`{"kind": "Listing", "data": {"children": []}}`,
+			http.StatusOK,
+			"not found",
+		},
+		{
+			"notFound",
+			"",
+			0,
+			"not found",
+		},
+	}
+
+	for _, c := range cases {
+		responses := map[string]string{}
+		if c.statusCode != 0 {
+			responses["/r/golang/about"] = c.aboutResponse
+		}
+		ts := subredditServer(responses, c.statusCode)
+		cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+
+		err := VerifySubreddit(context.Background(), cl, "golang")
+
+		if c.expectedReason == "" {
+			assert.Nil(t, err, c.name)
+		} else {
+			var subErr *SubredditError
+			assert.ErrorAs(t, err, &subErr, c.name)
+			assert.Equal(t, c.expectedReason, subErr.Reason, c.name)
+		}
+		ts.Close()
+	}
+}
@@ -0,0 +1,55 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"fmt"
+)
+
+const userUrl = "/user/"
+
+// UserAbout returns public profile of the user, including account age and karma
+func (cl *rateLimitedClient) UserAbout(ctx context.Context, username string) (account *api.Account, err error) {
+	var thing api.Thing
+	err = cl.getJson(ctx, "UserAbout", cl.host+userUrl+username+"/about", nil, &thing)
+	if err != nil {
+		return nil, err
+	}
+	account, ok := thing.Data.(*api.Account)
+	if !ok {
+		err = fmt.Errorf("unexpected thing kind %q in user about response: username=%v", thing.Kind, username)
+		return nil, err
+	}
+	return account, nil
+}
+
+// UserSubmitted walks posts submitted by the user
+func (cl *rateLimitedClient) UserSubmitted(username string, opts api.UserHistoryOptions) api.ListingIterator {
+	return cl.newUserHistoryIterator("UserSubmitted", username, "submitted", opts)
+}
+
+// UserComments walks comments of the user
+func (cl *rateLimitedClient) UserComments(username string, opts api.UserHistoryOptions) api.ListingIterator {
+	return cl.newUserHistoryIterator("UserComments", username, "comments", opts)
+}
+
+// UserSaved walks posts and comments saved by the user, it's available for the authorized user only
+func (cl *rateLimitedClient) UserSaved(username string, opts api.UserHistoryOptions) api.ListingIterator {
+	return cl.newUserHistoryIterator("UserSaved", username, "saved", opts)
+}
+
+// UserUpvoted walks posts upvoted by the user, it's available for the authorized user only
+func (cl *rateLimitedClient) UserUpvoted(username string, opts api.UserHistoryOptions) api.ListingIterator {
+	return cl.newUserHistoryIterator("UserUpvoted", username, "upvoted", opts)
+}
+
+func (cl *rateLimitedClient) newUserHistoryIterator(method string, username string, where string, opts api.UserHistoryOptions) *listingIterator {
+	params := make(map[string]string)
+	if opts.Sort != "" {
+		params["sort"] = opts.Sort
+	}
+	err := setTimeParam(params, method, opts.Time)
+	it := cl.newListingIterator(method, cl.host+userUrl+username+"/"+where, params, opts.ListingOptions)
+	it.err = err
+	return it
+}
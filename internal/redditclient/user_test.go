@@ -0,0 +1,121 @@
+package redditclient
+
+import (
+	"context"
+	"dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserAbout(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(remainingHeader, "600")
+		w.Header().Add(usedHeader, "0")
+		w.Header().Add(resetHeader, "600")
+		if r.URL.Path != "/user/gopher42/about" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"kind": "t2", "data": {"id": "abc", "name": "gopher42", "link_karma": 100, "comment_karma": 4500, "total_karma": 4600, "created_utc": 1400000000.0}}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+	cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{})
+
+	account, err := cl.UserAbout(context.Background(), "gopher42")
+	assert.Nil(t, err)
+	assert.Equal(t, "gopher42", account.Name)
+	assert.Equal(t, 4600, account.TotalKarma)
+	assert.Equal(t, time.Unix(1400000000, 0).UTC(), account.Created)
+
+	_, err = cl.UserAbout(context.Background(), "nobody")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUserHistory(t *testing.T) {
+	responseBytes := readFile("../../testdata/api/mixedListing.json", t)
+	cases := []struct {
+		name          string
+		iterator      func(cl api.RedditAPIClient) api.ListingIterator
+		appOnly       bool
+		expectedQuery string
+		expectedErr   bool
+	}{
+		{
+			"submitted",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.UserSubmitted("gopher42", api.UserHistoryOptions{ListingOptions: api.ListingOptions{Limit: 100}})
+			},
+			true,
+			"/user/gopher42/submitted?limit=100",
+			false,
+		},
+		{
+			"topComments",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.UserComments("gopher42", api.UserHistoryOptions{Sort: "top", Time: api.TimeYear})
+			},
+			false,
+			"/user/gopher42/comments?limit=25&sort=top&t=year",
+			false,
+		},
+		{
+			"saved",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.UserSaved("dmmakbot", api.UserHistoryOptions{})
+			},
+			false,
+			"/user/dmmakbot/saved?limit=25",
+			false,
+		},
+		{
+			"upvoted",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.UserUpvoted("dmmakbot", api.UserHistoryOptions{})
+			},
+			false,
+			"/user/dmmakbot/upvoted?limit=25",
+			false,
+		},
+		{
+			"savedAppOnly",
+			func(cl api.RedditAPIClient) api.ListingIterator {
+				return cl.UserSaved("dmmakbot", api.UserHistoryOptions{})
+			},
+			true,
+			"",
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		var query string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Path + "?" + r.URL.RawQuery
+			w.Header().Add(remainingHeader, "600")
+			w.Header().Add(usedHeader, "0")
+			w.Header().Add(resetHeader, "600")
+			w.Write(responseBytes)
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+
+		cl := NewClient(ts.URL, "/new", "/api/save", "dummy", &TokenPollerMock{appOnly: c.appOnly})
+		it := c.iterator(cl)
+		ok := it.Next(context.Background())
+
+		assert.Equal(t, c.expectedQuery, query, c.name)
+		if c.expectedErr {
+			assert.False(t, ok, c.name)
+			assert.NotNil(t, it.Err(), c.name)
+		} else {
+			assert.True(t, ok, c.name)
+			assert.Nil(t, it.Err(), c.name)
+			assert.NotEmpty(t, it.Page().Comments(), c.name)
+		}
+		ts.Close()
+	}
+}
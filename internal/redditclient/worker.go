@@ -3,6 +3,7 @@ package redditclient
 import (
 	"context"
 	"dmmak/redditapi/internal/api"
+	"errors"
 	"log"
 	"strings"
 	"time"
@@ -11,11 +12,50 @@ import (
 type worker struct {
 	subreddit     string
 	lastPostName  string // keep track of searched posts
+	failures      int    // consecutive listing requests rejected with 403 or 404
 	keywords      []string
 	requestPeriod uint
+	readOnly      bool // matching posts are only logged, e.g. app-only accounts can't save them
 	cl            api.RedditAPIClient
 }
 
+// maxListingFailures is the number of consecutive 403 or 404 listing responses the worker stops after,
+// as single ones could be transient
+const maxListingFailures = 3
+
+var (
+	// WorkerMethods are API methods called by subreddit workers
+	WorkerMethods = []string{"SubredditAbout", "GetNewPosts", "SavePost"}
+	// ReadOnlyWorkerMethods are API methods called by read-only subreddit workers
+	ReadOnlyWorkerMethods = []string{"SubredditAbout", "GetNewPosts"}
+)
+
+// VerifySubreddit checks that the subreddit exists, is readable by everyone and isn't quarantined
+func VerifySubreddit(ctx context.Context, cl api.RedditAPIClient, name string) (err error) {
+	sub, err := cl.SubredditAbout(ctx, name)
+	var apiErr *APIError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return &SubredditError{Subreddit: name, Reason: "not found", Err: err}
+	case errors.Is(err, ErrForbidden) && errors.As(err, &apiErr):
+		// reason is e.g. private, banned or quarantined
+		reason := apiErr.Reason
+		if reason == "" {
+			reason = "forbidden"
+		}
+		return &SubredditError{Subreddit: name, Reason: reason, Err: err}
+	case err != nil:
+		return err
+	}
+	if sub.Quarantine {
+		return &SubredditError{Subreddit: name, Reason: "quarantined"}
+	}
+	if sub.SubredditType != api.SubredditPublic && sub.SubredditType != api.SubredditRestricted {
+		return &SubredditError{Subreddit: name, Reason: sub.SubredditType}
+	}
+	return nil
+}
+
 func NewWorker(subreddit string, keywords string, requestPeriod uint, cl api.RedditAPIClient) (w *worker) {
 	splitted := strings.Split(keywords, ",")
 	w = &worker{
@@ -27,11 +67,20 @@ func NewWorker(subreddit string, keywords string, requestPeriod uint, cl api.Red
 	return w
 }
 
+// NewReadOnlyWorker creates a worker which logs matching posts instead of saving them
+func NewReadOnlyWorker(subreddit string, keywords string, requestPeriod uint, cl api.RedditAPIClient) (w *worker) {
+	w = NewWorker(subreddit, keywords, requestPeriod, cl)
+	w.readOnly = true
+	return w
+}
+
 func (w *worker) DoWork(ctx context.Context) {
 	log.Printf("Start worker for subreddit \"%v\"\n", w.subreddit)
 	defer log.Printf("Worker for subreddit \"%v\" is shutted\n", w.subreddit)
 
-	w.saveNewPosts(ctx)
+	if !w.saveNewPosts(ctx) {
+		return
+	}
 	ticker := time.NewTicker(time.Duration(w.requestPeriod) * time.Second)
 	for {
 		select {
@@ -39,39 +88,65 @@ func (w *worker) DoWork(ctx context.Context) {
 			if ctx.Err() != nil {
 				return
 			}
-			w.saveNewPosts(ctx)
+			if !w.saveNewPosts(ctx) {
+				return
+			}
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (w *worker) saveNewPosts(ctx context.Context) {
+// saveNewPosts returns false if the subreddit can't be polled anymore
+func (w *worker) saveNewPosts(ctx context.Context) (ok bool) {
 	newPostsResp, err := w.cl.GetNewPosts(ctx, w.subreddit, w.lastPostName)
-	if err != nil {
+	switch {
+	case errors.Is(err, ErrSubredditBanned), errors.Is(err, ErrSubredditPrivate):
+		// there is no point to retry
+		log.Printf("Subreddit \"%v\" isn't accessible, stop polling it: %v\n", w.subreddit, err)
+		return false
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrNotFound):
+		w.failures++
+		if w.failures >= maxListingFailures {
+			log.Printf("Subreddit \"%v\" isn't accessible %v times in a row, stop polling it: %v\n", w.subreddit, w.failures, err)
+			return false
+		}
+		log.Printf("Subreddit \"%v\" isn't accessible, retry on next tick: %v\n", w.subreddit, err)
+		return true
+	case errors.Is(err, ErrRateLimited):
+		log.Printf("Getting new posts for subreddit \"%v\" is rate limited, retry on next tick\n", w.subreddit)
+		return true
+	case err != nil:
 		log.Printf("Error while getting new posts for subreddit \"%v\": %v\n", w.subreddit, err)
-		return
+		return true
 	}
 
-	newPosts := newPostsResp.Data.Children
+	w.failures = 0
+
+	newPosts := newPostsResp.Links()
 	if len(newPosts) == 0 {
 		log.Printf("No new posts in subreddit %v\n", w.subreddit)
-		return
+		return true
 	}
 	log.Printf("Found %v new posts in subreddit %v\n", len(newPosts), w.subreddit)
 
-	w.lastPostName = newPosts[0].Data.Name
+	w.lastPostName = newPosts[0].Name
 	for _, post := range newPosts {
 		for _, word := range w.keywords {
-			if strings.Contains(post.Data.Title, word) {
-
-				log.Printf("Save post id=%v from subreddit \"%v\"\n", post.Data.Name, w.subreddit)
-				err = w.cl.SavePost(ctx, post.Data.Name)
+			if strings.Contains(post.Title, word) {
+				if w.readOnly {
+					log.Printf("Found post id=%v matching keywords in subreddit \"%v\"\n", post.Name, w.subreddit)
+					break
+				}
+				log.Printf("Save post id=%v from subreddit \"%v\"\n", post.Name, w.subreddit)
+				err = w.cl.SavePost(ctx, post.Name, "")
 				if err != nil {
-					log.Printf("Save post id=%v from subreddit \"%v\": %v\n", post.Data.Name, w.subreddit, err)
+					// e.g. the post has been deleted meanwhile, so it's skipped
+					log.Printf("Save post id=%v from subreddit \"%v\": %v\n", post.Name, w.subreddit, err)
 				}
 				break
 			}
 		}
 	}
+	return true
 }
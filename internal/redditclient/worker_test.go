@@ -3,27 +3,38 @@ package redditclient
 import (
 	"context"
 	. "dmmak/redditapi/internal/api"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type StubRedditAPIClient struct {
+	RedditAPIClient // methods which aren't used by the worker
+	newPostsErr     error
+	saveErr         error
+	saved           []string
 }
 
-func (cl *StubRedditAPIClient) GetNewPosts(ctx context.Context, subreddit, lastPostName string) (r *NewPostsResponse, err error) {
-	r = &NewPostsResponse{
-		Data: NewPostsResponseData{
-			Children: []NewPostsResponseChildren{
-				{
-					Data: NewPostsResponseChildrenData{
-						Title: "postTitle1",
-						Name:  "postName1",
-					},
+func (cl *StubRedditAPIClient) GetNewPosts(ctx context.Context, subreddit, lastPostName string) (r *Listing, err error) {
+	if cl.newPostsErr != nil {
+		return nil, cl.newPostsErr
+	}
+	r = &Listing{
+		Children: []Thing{
+			{
+				Kind: KindLink,
+				Data: &Link{
+					Title: "postTitle1",
+					Name:  "postName1",
 				},
-				{
-					Data: NewPostsResponseChildrenData{
-						Title: "postTitle1",
-						Name:  "postName1",
-					},
+			},
+			{
+				Kind: KindLink,
+				Data: &Link{
+					Title: "postTitle1",
+					Name:  "postName1",
 				},
 			},
 		},
@@ -31,7 +42,16 @@ func (cl *StubRedditAPIClient) GetNewPosts(ctx context.Context, subreddit, lastP
 	return r, nil
 }
 
-func (cl *StubRedditAPIClient) SavePost(ctx context.Context, name string) (err error) {
+func (cl *StubRedditAPIClient) NewPosts(subreddit string, opts ListingOptions) ListingIterator {
+	return nil
+}
+
+func (cl *StubRedditAPIClient) SavePost(ctx context.Context, name string, category string) (err error) {
+	cl.saved = append(cl.saved, name)
+	return cl.saveErr
+}
+
+func (cl *StubRedditAPIClient) VerifyScopes(methods ...string) (err error) {
 	return nil
 }
 
@@ -42,3 +62,86 @@ func TestDoWork(t *testing.T) {
 	cancel()
 	worker.DoWork(ctx)
 }
+
+func TestDoWorkSubredditInaccessible(t *testing.T) {
+	cl := &StubRedditAPIClient{newPostsErr: &APIError{StatusCode: http.StatusForbidden, Reason: "private"}}
+	worker := NewWorker("subreddit1", "postTitle1", 180, cl)
+	// worker stops by itself, context is never cancelled
+	worker.DoWork(context.Background())
+}
+
+func TestReadOnlyWorkerAppOnly(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %v", r.URL.Path)
+	}))
+	defer ts.Close()
+	cl := NewClient(ts.URL, "/new", "/save", "dummy", &TokenPollerMock{appOnly: true})
+
+	assert.NotNil(t, cl.VerifyScopes(WorkerMethods...))
+	assert.Nil(t, cl.VerifyScopes(ReadOnlyWorkerMethods...))
+
+	stub := &StubRedditAPIClient{}
+	worker := NewReadOnlyWorker("subreddit1", "postTitle1", 180, stub)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	worker.DoWork(ctx)
+
+	assert.Empty(t, stub.saved)
+	assert.Equal(t, "postName1", worker.lastPostName)
+}
+
+func TestSaveNewPostsErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		cl          *StubRedditAPIClient
+		expectedOks []bool
+	}{
+		{
+			"banned",
+			&StubRedditAPIClient{newPostsErr: &APIError{StatusCode: http.StatusNotFound, Reason: "banned"}},
+			[]bool{false},
+		},
+		{
+			"private",
+			&StubRedditAPIClient{newPostsErr: &APIError{StatusCode: http.StatusForbidden, Reason: "private"}},
+			[]bool{false},
+		},
+		{
+			"transientForbidden",
+			&StubRedditAPIClient{newPostsErr: &APIError{StatusCode: http.StatusForbidden}},
+			[]bool{true, true, false},
+		},
+		{
+			"notFound",
+			&StubRedditAPIClient{newPostsErr: &APIError{StatusCode: http.StatusNotFound}},
+			[]bool{true, true, false},
+		},
+		{
+			"saveError",
+			&StubRedditAPIClient{saveErr: &APIError{StatusCode: http.StatusNotFound}},
+			[]bool{true, true, true},
+		},
+	}
+	for _, c := range cases {
+		worker := NewWorker("subreddit1", "postTitle1", 180, c.cl)
+		var actual []bool
+		for range c.expectedOks {
+			actual = append(actual, worker.saveNewPosts(context.Background()))
+		}
+		assert.Equal(t, c.expectedOks, actual, c.name)
+	}
+}
+
+func TestSaveNewPostsResetsFailures(t *testing.T) {
+	cl := &StubRedditAPIClient{newPostsErr: &APIError{StatusCode: http.StatusForbidden}}
+	worker := NewWorker("subreddit1", "postTitle1", 180, cl)
+	assert.True(t, worker.saveNewPosts(context.Background()))
+	assert.True(t, worker.saveNewPosts(context.Background()))
+
+	cl.newPostsErr = nil
+	assert.True(t, worker.saveNewPosts(context.Background()))
+	assert.Equal(t, 0, worker.failures)
+
+	cl.newPostsErr = &APIError{StatusCode: http.StatusForbidden}
+	assert.True(t, worker.saveNewPosts(context.Background()))
+}